@@ -14,6 +14,7 @@ import (
 	"go.microcore.dev/framework/errors"
 	"go.microcore.dev/framework/transport/http"
 	"go.microcore.dev/framework/transport/http/client"
+	"go.microcore.dev/sdk/types"
 )
 
 type Config struct {
@@ -245,7 +246,12 @@ func (a *adapter) CreateRole(ctx context.Context, authToken string, data CreateR
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
-func (a *adapter) FilterRoles(ctx context.Context, authToken string, data FilterRolesData) ([]FilterRolesResult, error) {
+func (a *adapter) FilterRoles(ctx context.Context, authToken string, data FilterRolesData) (*types.Page[FilterRolesResult], error) {
+	// Validate filter
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.authServiceEndpoint)
@@ -273,11 +279,11 @@ func (a *adapter) FilterRoles(ctx context.Context, authToken string, data Filter
 
 	// Check success status code
 	if res.StatusCode() == 200 {
-		var response []FilterRolesResult
+		var response types.Page[FilterRolesResult]
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
 		}
-		return response, nil
+		return &response, nil
 	}
 
 	// Response message
@@ -434,7 +440,12 @@ func (a *adapter) CreateHttpRule(ctx context.Context, authToken string, data Cre
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
-func (a *adapter) FilterHttpRules(ctx context.Context, authToken string, data FilterHttpRulesData) ([]FilterHttpRulesResult, error) {
+func (a *adapter) FilterHttpRules(ctx context.Context, authToken string, data FilterHttpRulesData) (*types.Page[FilterHttpRulesResult], error) {
+	// Validate filter
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.authServiceEndpoint)
@@ -462,11 +473,11 @@ func (a *adapter) FilterHttpRules(ctx context.Context, authToken string, data Fi
 
 	// Check success status code
 	if res.StatusCode() == 200 {
-		var response []FilterHttpRulesResult
+		var response types.Page[FilterHttpRulesResult]
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
 		}
-		return response, nil
+		return &response, nil
 	}
 	
 	// Response message
@@ -893,7 +904,12 @@ func (a *adapter) CreateStaticAccessToken(ctx context.Context, authToken string,
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
-func (a *adapter) FilterStaticAccessTokens(ctx context.Context, authToken string, data FilterStaticAccessTokenData) ([]FilterStaticAccessTokenResult, error) {
+func (a *adapter) FilterStaticAccessTokens(ctx context.Context, authToken string, data FilterStaticAccessTokenData) (*types.Page[FilterStaticAccessTokenResult], error) {
+	// Validate filter
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.authServiceEndpoint)
@@ -921,11 +937,11 @@ func (a *adapter) FilterStaticAccessTokens(ctx context.Context, authToken string
 
 	// Check success status code
 	if res.StatusCode() == 200 {
-		var response []FilterStaticAccessTokenResult
+		var response types.Page[FilterStaticAccessTokenResult]
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
 		}
-		return response, nil
+		return &response, nil
 	}
 
 	// Response message
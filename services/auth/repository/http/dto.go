@@ -1,6 +1,10 @@
 package adapter
 
-import "time"
+import (
+	"time"
+
+	"go.microcore.dev/sdk/types"
+)
 
 // Data
 
@@ -17,10 +21,13 @@ type CreateRoleData struct {
 }
 
 type FilterRolesData struct {
-	Id          *[]string `json:"id,omitempty"`
-	Name        *[]string `json:"name,omitempty"`
-	SystemFlag  *bool     `json:"system_flag,omitempty"`
-	ServiceFlag *bool     `json:"service_flag,omitempty"`
+	Id          *types.Predicate[string]    `json:"id,omitempty"`
+	Name        *types.Predicate[string]    `json:"name,omitempty"`
+	SystemFlag  *bool                       `json:"system_flag,omitempty"`
+	ServiceFlag *bool                       `json:"service_flag,omitempty"`
+	Created     *types.Predicate[time.Time] `json:"created,omitempty"`
+	Updated     *types.Predicate[time.Time] `json:"updated,omitempty"`
+	types.PageParams
 }
 
 type UpdateRoleData struct {
@@ -37,11 +44,14 @@ type CreateHttpRuleData struct {
 }
 
 type FilterHttpRulesData struct {
-	Id      *[]uint   `json:"id,omitempty"`
-	RoleId  *[]string `json:"role_id,omitempty"`
-	Path    *[]string `json:"path,omitempty"`
-	Methods *[]string `json:"methods,omitempty"`
-	Mfa     *bool     `json:"mfa,omitempty"`
+	Id      *types.Predicate[uint]      `json:"id,omitempty"`
+	RoleId  *types.Predicate[string]    `json:"role_id,omitempty"`
+	Path    *types.Predicate[string]    `json:"path,omitempty"`
+	Methods *types.Predicate[string]    `json:"methods,omitempty"`
+	Mfa     *bool                       `json:"mfa,omitempty"`
+	Created *types.Predicate[time.Time] `json:"created,omitempty"`
+	Updated *types.Predicate[time.Time] `json:"updated,omitempty"`
+	types.PageParams
 }
 
 type UpdateHttpRuleData struct {
@@ -94,7 +104,9 @@ type CreateStaticAccessTokenData struct {
 }
 
 type FilterStaticAccessTokenData struct {
-	Id *[]string `json:"id,omitempty"`
+	Id      *types.Predicate[string]    `json:"id,omitempty"`
+	Created *types.Predicate[time.Time] `json:"created,omitempty"`
+	types.PageParams
 }
 
 // Results
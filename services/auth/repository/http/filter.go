@@ -0,0 +1,18 @@
+package adapter
+
+import "go.microcore.dev/sdk/types"
+
+// Validate checks the predicates and page params of the filter.
+func (d FilterRolesData) Validate() error {
+	return types.ValidateAll(d.Id, d.Name, d.Created, d.Updated, d.PageParams)
+}
+
+// Validate checks the predicates and page params of the filter.
+func (d FilterHttpRulesData) Validate() error {
+	return types.ValidateAll(d.Id, d.RoleId, d.Path, d.Methods, d.Created, d.Updated, d.PageParams)
+}
+
+// Validate checks the predicates and page params of the filter.
+func (d FilterStaticAccessTokenData) Validate() error {
+	return types.ValidateAll(d.Id, d.Created, d.PageParams)
+}
@@ -2,6 +2,8 @@ package adapter
 
 import (
 	"context"
+
+	"go.microcore.dev/sdk/types"
 )
 
 type Interface interface {
@@ -13,12 +15,12 @@ type Interface interface {
 	LogoutDevice(ctx context.Context, authToken string, data LogoutDeviceData) error
 	// Roles
 	CreateRole(ctx context.Context, authToken string, data CreateRoleData) (*CreateRoleResult, error)
-	FilterRoles(ctx context.Context, authToken string, data FilterRolesData) ([]FilterRolesResult, error)
+	FilterRoles(ctx context.Context, authToken string, data FilterRolesData) (*types.Page[FilterRolesResult], error)
 	UpdateRole(ctx context.Context, authToken string, id string, data UpdateRoleData) error
 	DeleteRole(ctx context.Context, authToken string, id string) error
 	// Rules (HTTP)
 	CreateHttpRule(ctx context.Context, authToken string, data CreateHttpRuleData) (*CreateHttpRuleResult, error)
-	FilterHttpRules(ctx context.Context, authToken string, data FilterHttpRulesData) ([]FilterHttpRulesResult, error)
+	FilterHttpRules(ctx context.Context, authToken string, data FilterHttpRulesData) (*types.Page[FilterHttpRulesResult], error)
 	UpdateHttpRule(ctx context.Context, authToken string, id uint, data UpdateHttpRuleData) error
 	DeleteHttpRule(ctx context.Context, authToken string, id uint) error
 	// Tokens
@@ -29,6 +31,6 @@ type Interface interface {
 	TokenAuthorizeHttp(ctx context.Context, authToken string, data TokenAuthorizeHttpData) (*TokenAuthorizeHttpResult, error)
 	// Static access tokens
 	CreateStaticAccessToken(ctx context.Context, authToken string, data CreateStaticAccessTokenData) (*CreateStaticAccessTokenResult, error)
-	FilterStaticAccessTokens(ctx context.Context, authToken string, data FilterStaticAccessTokenData) ([]FilterStaticAccessTokenResult, error)
+	FilterStaticAccessTokens(ctx context.Context, authToken string, data FilterStaticAccessTokenData) (*types.Page[FilterStaticAccessTokenResult], error)
 	DeleteStaticAccessToken(ctx context.Context, authToken string, id string) error
 }
@@ -0,0 +1,659 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"sync"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+const (
+	// Default plaintext size of an encrypted segment.
+	EncryptedDefaultSegmentSize = 64 << 10
+	// Data key length in bytes, AES-256.
+	EncryptedDataKeyLen = 32
+	// Max size of the encoded header in bytes.
+	EncryptedMaxHeaderSize = 4 << 10
+
+	encryptedMagic       = "MCE1"
+	encryptedAlgorithm   = "AES-256-GCM"
+	encryptedNoncePrefix = 7
+	// Plaintext size and SHA-256 of the plaintext
+	encryptedTrailerSize = 8 + sha256.Size
+	// Max cached download tokens and unwrapped data keys
+	encryptedMaxCached = 1024
+)
+
+// KeyProvider wraps and unwraps per-file data keys with a key-encryption
+// key, for example one stored in a KMS. WrapKey always uses the current
+// key, UnwrapKey any key that was current before.
+type KeyProvider interface {
+	WrapKey(ctx context.Context, dataKey []byte) (keyId string, wrappedKey []byte, err error)
+	UnwrapKey(ctx context.Context, keyId string, wrappedKey []byte) ([]byte, error)
+}
+
+type EncryptedConfig struct {
+	Files       adapter.Interface
+	KeyProvider KeyProvider
+	// Plaintext segment size, EncryptedDefaultSegmentSize if zero
+	SegmentSize int
+}
+
+// NewEncrypted returns a files adapter wrapper that encrypts uploads with
+// AES-256-GCM per-file data keys and decrypts downloads.
+//
+// A stored file is a header with the wrapped data key, the encrypted
+// segments and a trailer with the plaintext size and checksum. The
+// metadata is kept in the file rather than in a separate file next to it,
+// so it can not get out of sync with the content and rename, copy and move
+// work unchanged. StatFile and ListFiles report
+// the plaintext size and checksum from the trailer, StreamFileRange
+// decrypts only the segments covering the range. Share links would serve
+// the ciphertext, CreateShareLink returns ErrEncryptedShareLink.
+func NewEncrypted(config *EncryptedConfig) *Encrypted {
+	segmentSize := config.SegmentSize
+	if segmentSize <= 0 {
+		segmentSize = EncryptedDefaultSegmentSize
+	}
+	return &Encrypted{
+		Interface:   config.Files,
+		keyProvider: config.KeyProvider,
+		segmentSize: segmentSize,
+		tokens:      make(map[string]encryptedFile),
+		ciphers:     make(map[string]cipher.AEAD),
+	}
+}
+
+// Encrypted keeps the headers of tokens returned by DownloadFile and the
+// unwrapped data keys of recently read files in memory.
+type Encrypted struct {
+	adapter.Interface
+	keyProvider KeyProvider
+	segmentSize int
+
+	mu      sync.Mutex
+	tokens  map[string]encryptedFile
+	ciphers map[string]cipher.AEAD
+}
+
+// Header of a file of a download token
+type encryptedFile struct {
+	header     encryptedHeader
+	headerSize int64
+}
+
+// Header stored in front of the encrypted segments
+type encryptedHeader struct {
+	Algorithm   string `json:"alg"`
+	KeyId       string `json:"kid"`
+	WrappedKey  []byte `json:"key"`
+	NoncePrefix []byte `json:"nonce"`
+	SegmentSize int    `json:"segment_size"`
+}
+
+func (e *Encrypted) CreateFile(ctx context.Context, authToken string, data adapter.CreateFileData) error {
+	// Data key
+	dataKey := make([]byte, EncryptedDataKeyLen)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return err
+	}
+	keyId, wrappedKey, err := e.keyProvider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return fmt.Errorf("wrap data key: %w", err)
+	}
+
+	// Header
+	header := encryptedHeader{
+		Algorithm:   encryptedAlgorithm,
+		KeyId:       keyId,
+		WrappedKey:  wrappedKey,
+		NoncePrefix: make([]byte, encryptedNoncePrefix),
+		SegmentSize: e.segmentSize,
+	}
+	if _, err := io.ReadFull(rand.Reader, header.NoncePrefix); err != nil {
+		return err
+	}
+	headerBytes, err := marshalEncryptedHeader(header)
+	if err != nil {
+		return err
+	}
+
+	aead, err := newDataCipher(dataKey)
+	if err != nil {
+		return err
+	}
+
+	data.File = io.MultiReader(
+		bytes.NewReader(headerBytes),
+		&encryptReader{
+			src:         bufio.NewReaderSize(data.File, e.segmentSize+1),
+			aead:        aead,
+			noncePrefix: header.NoncePrefix,
+			plain:       make([]byte, e.segmentSize),
+			hash:        sha256.New(),
+		},
+	)
+	return e.Interface.CreateFile(ctx, authToken, data)
+}
+
+func (e *Encrypted) GetFile(ctx context.Context, authToken string, path string) ([]byte, error) {
+	data, err := e.Interface.GetFile(ctx, authToken, path)
+	if err != nil {
+		return nil, err
+	}
+	return e.decrypt(ctx, data)
+}
+
+// DownloadFile returns a stream token of the file and reads its header
+// ahead, so StreamFileRange of the token fetches only the segments of the
+// range. The checksum is not reported, the content is authenticated on
+// decryption.
+func (e *Encrypted) DownloadFile(ctx context.Context, authToken string, filePath string) (*adapter.DownloadFileResult, error) {
+	// Read header
+	download, err := e.Interface.DownloadFile(ctx, authToken, filePath)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := e.Interface.StreamFileRange(ctx, download.Token, 0, EncryptedMaxHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	header, headerSize, err := parseEncryptedHeader(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stream tokens are single use, request another one for the caller
+	download, err = e.Interface.DownloadFile(ctx, authToken, filePath)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	if len(e.tokens) >= encryptedMaxCached {
+		clear(e.tokens)
+	}
+	e.tokens[download.Token] = encryptedFile{header, headerSize}
+	e.mu.Unlock()
+
+	return &adapter.DownloadFileResult{Token: download.Token}, nil
+}
+
+func (e *Encrypted) StreamFile(ctx context.Context, token string) ([]byte, error) {
+	e.takeToken(token)
+	data, err := e.Interface.StreamFile(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return e.decrypt(ctx, data)
+}
+
+// StreamFileRange decrypts the segments covering the plaintext range.
+// Tokens not returned by DownloadFile of the wrapper have no header read
+// ahead, the whole file is decrypted for them.
+func (e *Encrypted) StreamFileRange(ctx context.Context, token string, offset int64, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 {
+		return nil, adapter.ErrFileInvalidRange
+	}
+
+	file, ok := e.takeToken(token)
+	if !ok {
+		data, err := e.StreamFile(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		return cutRange(data, offset, length)
+	}
+
+	aead, err := e.dataCipher(ctx, file.header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Covering segments, with one byte more than the trailer behind them
+	// to tell whether the last one is the final segment
+	segmentSize := int64(file.header.SegmentSize)
+	sealedSize := segmentSize + int64(aead.Overhead())
+	first := offset / segmentSize
+	var want int64
+	if length > 0 {
+		count := (offset+length-1)/segmentSize - first + 1
+		want = count*sealedSize + encryptedTrailerSize + 1
+	}
+	data, err := e.Interface.StreamFileRange(ctx, token, file.headerSize+first*sealedSize, want)
+	if err != nil {
+		return nil, err
+	}
+	atEnd := want == 0 || int64(len(data)) < want
+	if !atEnd {
+		data = data[:want-encryptedTrailerSize-1]
+	}
+
+	plain, err := openSegments(aead, file.header, data, uint32(first), atEnd)
+	if err != nil {
+		return nil, err
+	}
+	return cutRange(plain, offset-first*segmentSize, length)
+}
+
+// StatFile reports the plaintext size and SHA-256 checksum of a file from
+// its trailer. They are authenticated when the content is decrypted.
+func (e *Encrypted) StatFile(ctx context.Context, authToken string, filePath string) (*adapter.StatFileResult, error) {
+	stat, err := e.Interface.StatFile(ctx, authToken, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir || stat.Size == nil {
+		return stat, nil
+	}
+	size, checksum, err := e.readTrailer(ctx, authToken, filePath, *stat.Size)
+	if err != nil {
+		return nil, err
+	}
+	stat.Size, stat.Checksum = &size, &checksum
+	return stat, nil
+}
+
+// ListFiles reports the plaintext sizes of the files, reading the trailer
+// of every file.
+func (e *Encrypted) ListFiles(ctx context.Context, authToken string, dirPath string) ([]adapter.FileResult, error) {
+	list, err := e.Interface.ListFiles(ctx, authToken, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	for i, file := range list {
+		if file.IsDir || file.Size == nil {
+			continue
+		}
+		size, _, err := e.readTrailer(ctx, authToken, path.Join(dirPath, file.Name), *file.Size)
+		if err != nil {
+			return nil, err
+		}
+		list[i].Size = &size
+	}
+	return list, nil
+}
+
+// DownloadArchive returns an archive of the decrypted files and dirs at
+// paths.
+func (e *Encrypted) DownloadArchive(ctx context.Context, authToken string, paths []string, format adapter.ArchiveFormat) (io.ReadCloser, error) {
+	return adapter.NewArchiveReader(ctx, e, authToken, paths, format)
+}
+
+// UploadArchive unpacks an archive and encrypts its files.
+func (e *Encrypted) UploadArchive(ctx context.Context, authToken string, data adapter.UploadArchiveData) (*adapter.UploadArchiveResult, error) {
+	return adapter.ExtractArchive(ctx, e, authToken, data)
+}
+
+// CreateShareLink returns ErrEncryptedShareLink, a link would serve the
+// ciphertext.
+func (e *Encrypted) CreateShareLink(ctx context.Context, authToken string, path string, data adapter.CreateShareLinkData) (*adapter.ShareLinkResult, error) {
+	return nil, ErrEncryptedShareLink
+}
+
+// Rewrap wraps the data key of the file with the current key-encryption
+// key of the KeyProvider. The content is not re-encrypted, the file is
+// replaced with a server-side move.
+func (e *Encrypted) Rewrap(ctx context.Context, authToken string, filePath string) error {
+	data, err := e.Interface.GetFile(ctx, authToken, filePath)
+	if err != nil {
+		return err
+	}
+
+	header, headerSize, err := parseEncryptedHeader(data)
+	if err != nil {
+		return err
+	}
+	dataKey, err := e.keyProvider.UnwrapKey(ctx, header.KeyId, header.WrappedKey)
+	if err != nil {
+		return fmt.Errorf("unwrap data key: %w", err)
+	}
+	header.KeyId, header.WrappedKey, err = e.keyProvider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return fmt.Errorf("wrap data key: %w", err)
+	}
+	headerBytes, err := marshalEncryptedHeader(header)
+	if err != nil {
+		return err
+	}
+
+	// Upload next to the file and replace it
+	return adapter.ReplaceFile(ctx, e.Interface, authToken, adapter.CreateFileData{
+		Path: path.Dir(filePath),
+		File: io.MultiReader(bytes.NewReader(headerBytes), bytes.NewReader(data[headerSize:])),
+		Name: path.Base(filePath),
+	})
+}
+
+// Helper for decrypt stored content and check the trailer checksum
+func (e *Encrypted) decrypt(ctx context.Context, data []byte) ([]byte, error) {
+	header, headerSize, err := parseEncryptedHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := e.dataCipher(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := openSegments(aead, header, data[headerSize:], 0, true)
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(plain)
+	if !bytes.Equal(checksum[:], data[len(data)-sha256.Size:]) {
+		return nil, ErrEncryptedDecrypt
+	}
+	return plain, nil
+}
+
+// Helper for take the read ahead header of a download token
+func (e *Encrypted) takeToken(token string) (encryptedFile, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	file, ok := e.tokens[token]
+	delete(e.tokens, token)
+	return file, ok
+}
+
+// Helper for unwrap the data key of a header, keys are cached by the
+// wrapped key so range reads do not unwrap per block
+func (e *Encrypted) dataCipher(ctx context.Context, header encryptedHeader) (cipher.AEAD, error) {
+	cacheKey := header.KeyId + ":" + string(header.WrappedKey)
+	e.mu.Lock()
+	aead, ok := e.ciphers[cacheKey]
+	e.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	dataKey, err := e.keyProvider.UnwrapKey(ctx, header.KeyId, header.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	aead, err = newDataCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	if len(e.ciphers) >= encryptedMaxCached {
+		clear(e.ciphers)
+	}
+	e.ciphers[cacheKey] = aead
+	e.mu.Unlock()
+
+	return aead, nil
+}
+
+// Helper for read the plaintext size and hex encoded checksum from the
+// trailer of a stored file
+func (e *Encrypted) readTrailer(ctx context.Context, authToken string, filePath string, storedSize int64) (int64, string, error) {
+	if storedSize < encryptedTrailerSize {
+		return 0, "", ErrEncryptedInvalidHeader
+	}
+	download, err := e.Interface.DownloadFile(ctx, authToken, filePath)
+	if err != nil {
+		return 0, "", err
+	}
+	trailer, err := e.Interface.StreamFileRange(ctx, download.Token, storedSize-encryptedTrailerSize, encryptedTrailerSize)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(trailer) != encryptedTrailerSize {
+		return 0, "", ErrEncryptedInvalidHeader
+	}
+	size := binary.BigEndian.Uint64(trailer)
+	if size > uint64(storedSize) {
+		return 0, "", ErrEncryptedInvalidHeader
+	}
+	return int64(size), hex.EncodeToString(trailer[8:]), nil
+}
+
+// Helper for open consecutive segments starting at counter. At the end of
+// the file data ends with the trailer, which is authenticated with the
+// final segment and must match the plaintext size.
+func openSegments(aead cipher.AEAD, header encryptedHeader, data []byte, counter uint32, atEnd bool) ([]byte, error) {
+	var trailer []byte
+	if atEnd {
+		if len(data) < encryptedTrailerSize && counter > 0 {
+			// Range starts inside the trailer
+			return nil, adapter.ErrFileInvalidRange
+		}
+		if len(data) < encryptedTrailerSize {
+			return nil, ErrEncryptedDecrypt
+		}
+		data, trailer = data[:len(data)-encryptedTrailerSize], data[len(data)-encryptedTrailerSize:]
+	}
+
+	sealedSize := header.SegmentSize + aead.Overhead()
+	offset := uint64(counter) * uint64(header.SegmentSize)
+	if atEnd && len(data) == 0 {
+		// Range starts behind the final segment
+		if binary.BigEndian.Uint64(trailer) != offset {
+			return nil, adapter.ErrFileInvalidRange
+		}
+		return []byte{}, nil
+	}
+
+	plain := make([]byte, 0, len(data))
+	for len(data) > 0 {
+		size := min(sealedSize, len(data))
+		last := atEnd && size == len(data)
+		var additional []byte
+		if last {
+			additional = trailer
+		}
+		var err error
+		plain, err = aead.Open(plain, segmentNonce(header.NoncePrefix, counter, last), data[:size], additional)
+		if err != nil {
+			return nil, ErrEncryptedDecrypt
+		}
+		data = data[size:]
+		counter++
+	}
+	if atEnd && binary.BigEndian.Uint64(trailer) != offset+uint64(len(plain)) {
+		return nil, ErrEncryptedDecrypt
+	}
+
+	return plain, nil
+}
+
+// Helper for cut a plaintext range, zero length means up to the end
+func cutRange(data []byte, offset int64, length int64) ([]byte, error) {
+	if offset > int64(len(data)) {
+		return nil, adapter.ErrFileInvalidRange
+	}
+	end := int64(len(data))
+	if length > 0 && offset+length < end {
+		end = offset + length
+	}
+	return data[offset:end], nil
+}
+
+// Helper for build the data key cipher
+func newDataCipher(dataKey []byte) (cipher.AEAD, error) {
+	if len(dataKey) != EncryptedDataKeyLen {
+		return nil, ErrEncryptedInvalidHeader
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Helper for build segment nonce: prefix, counter and last segment flag
+func segmentNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, encryptedNoncePrefix+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// Helper for encode header: magic, header length and json header
+func marshalEncryptedHeader(header encryptedHeader) ([]byte, error) {
+	body, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if len(encryptedMagic)+4+len(body) > EncryptedMaxHeaderSize {
+		return nil, ErrEncryptedInvalidHeader
+	}
+	data := make([]byte, 0, len(encryptedMagic)+4+len(body))
+	data = append(data, encryptedMagic...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(body)))
+	return append(data, body...), nil
+}
+
+// Helper for decode header from the start of a file, returns the header
+// and its encoded size
+func parseEncryptedHeader(data []byte) (encryptedHeader, int64, error) {
+	var header encryptedHeader
+
+	prefix := len(encryptedMagic) + 4
+	if len(data) < prefix || string(data[:len(encryptedMagic)]) != encryptedMagic {
+		return header, 0, ErrEncryptedInvalidHeader
+	}
+	size := binary.BigEndian.Uint32(data[len(encryptedMagic):prefix])
+	if uint64(size) > uint64(len(data)-prefix) {
+		return header, 0, ErrEncryptedInvalidHeader
+	}
+	if err := json.Unmarshal(data[prefix:prefix+int(size)], &header); err != nil {
+		return header, 0, ErrEncryptedInvalidHeader
+	}
+	if header.Algorithm != encryptedAlgorithm || header.SegmentSize <= 0 || len(header.NoncePrefix) != encryptedNoncePrefix {
+		return header, 0, ErrEncryptedInvalidHeader
+	}
+
+	return header, int64(prefix) + int64(size), nil
+}
+
+// Encrypt reader
+
+type encryptReader struct {
+	src         *bufio.Reader
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	plain       []byte
+	buf         []byte
+	sealed      []byte
+	done        bool
+	hash        hash.Hash
+	size        uint64
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.sealed) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.sealed)
+	r.sealed = r.sealed[n:]
+	return n, nil
+}
+
+// Helper for seal the next segment, the last one is flagged in the nonce
+// and followed by the trailer it authenticates
+func (r *encryptReader) next() error {
+	n, err := io.ReadFull(r.src, r.plain)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if err == nil {
+		if _, err := r.src.Peek(1); err == io.EOF {
+			r.done = true
+		} else if err != nil {
+			return err
+		}
+	} else {
+		r.done = true
+	}
+
+	r.hash.Write(r.plain[:n])
+	r.size += uint64(n)
+
+	nonce := segmentNonce(r.noncePrefix, r.counter, r.done)
+	if r.done {
+		trailer := binary.BigEndian.AppendUint64(nil, r.size)
+		trailer = r.hash.Sum(trailer)
+		r.buf = r.aead.Seal(r.buf[:0], nonce, r.plain[:n], trailer)
+		r.buf = append(r.buf, trailer...)
+	} else {
+		r.buf = r.aead.Seal(r.buf[:0], nonce, r.plain[:n], nil)
+	}
+	r.sealed = r.buf
+	r.counter++
+	return nil
+}
+
+// Static key provider
+
+// NewStaticKeyProvider returns a KeyProvider with in-process AES-256
+// key-encryption keys. New data keys are wrapped with the current key,
+// older keys are kept to unwrap existing files.
+func NewStaticKeyProvider(current string, keys map[string][]byte) (KeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("current key %s not found", current)
+	}
+	for id, key := range keys {
+		if len(key) != EncryptedDataKeyLen {
+			return nil, fmt.Errorf("key %s: must be %d bytes", id, EncryptedDataKeyLen)
+		}
+	}
+	return &staticKeyProvider{current, keys}, nil
+}
+
+type staticKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+func (p *staticKeyProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	aead, err := newDataCipher(p.keys[p.current])
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, err
+	}
+	return p.current, aead.Seal(nonce, nonce, dataKey, []byte(p.current)), nil
+}
+
+func (p *staticKeyProvider) UnwrapKey(ctx context.Context, keyId string, wrappedKey []byte) ([]byte, error) {
+	key, ok := p.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", keyId)
+	}
+	aead, err := newDataCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < aead.NonceSize() {
+		return nil, errors.New("wrapped key too short")
+	}
+	nonce, sealed := wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, []byte(keyId))
+}
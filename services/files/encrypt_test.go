@@ -0,0 +1,150 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+const testSegmentSize = 16
+
+// Files adapter keeping the content of the last upload
+type uploadRecorder struct {
+	adapter.Interface
+	stored []byte
+}
+
+func (r *uploadRecorder) CreateFile(ctx context.Context, authToken string, data adapter.CreateFileData) error {
+	stored, err := io.ReadAll(data.File)
+	r.stored = stored
+	return err
+}
+
+func newTestEncrypted(t *testing.T) (*Encrypted, *uploadRecorder) {
+	t.Helper()
+	key := make([]byte, EncryptedDataKeyLen)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	keyProvider, err := NewStaticKeyProvider("k1", map[string][]byte{"k1": key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := &uploadRecorder{}
+	return NewEncrypted(&EncryptedConfig{
+		Files:       recorder,
+		KeyProvider: keyProvider,
+		SegmentSize: testSegmentSize,
+	}), recorder
+}
+
+func encryptTestContent(t *testing.T, e *Encrypted, recorder *uploadRecorder, content []byte) []byte {
+	t.Helper()
+	err := e.CreateFile(context.Background(), "token", adapter.CreateFileData{
+		Path: "/",
+		Name: "file.bin",
+		File: bytes.NewReader(content),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recorder.stored
+}
+
+func TestEncryptedRoundTrip(t *testing.T) {
+	e, recorder := newTestEncrypted(t)
+
+	for _, size := range []int{0, 1, testSegmentSize - 1, testSegmentSize, testSegmentSize + 1, 3 * testSegmentSize, 100} {
+		content := make([]byte, size)
+		if _, err := rand.Read(content); err != nil {
+			t.Fatal(err)
+		}
+		stored := encryptTestContent(t, e, recorder, content)
+		if size > 8 && bytes.Contains(stored, content) {
+			t.Fatalf("size %d: plaintext stored", size)
+		}
+		got, err := e.decrypt(context.Background(), stored)
+		if err != nil {
+			t.Fatalf("size %d: decrypt: %v", size, err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Fatalf("size %d: decrypted content differs", size)
+		}
+	}
+}
+
+func TestEncryptedTruncated(t *testing.T) {
+	e, recorder := newTestEncrypted(t)
+	content := make([]byte, 3*testSegmentSize+5)
+	stored := encryptTestContent(t, e, recorder, content)
+	_, headerSize, err := parseEncryptedHeader(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealedSize := testSegmentSize + 16 // GCM tag
+
+	tests := map[string][]byte{
+		// Final segment and trailer cut off at a segment boundary
+		"final segment": stored[:int(headerSize)+3*sealedSize],
+		// Part of the final segment cut off, trailer kept
+		"final segment bytes": append(
+			append([]byte(nil), stored[:len(stored)-encryptedTrailerSize-2]...),
+			stored[len(stored)-encryptedTrailerSize:]...,
+		),
+		"trailer":       stored[:len(stored)-encryptedTrailerSize],
+		"trailer bytes": stored[:len(stored)-1],
+	}
+	for name, data := range tests {
+		if _, err := e.decrypt(context.Background(), data); !errors.Is(err, ErrEncryptedDecrypt) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrEncryptedDecrypt)
+		}
+	}
+}
+
+func TestEncryptedLastFlag(t *testing.T) {
+	ctx := context.Background()
+	e, recorder := newTestEncrypted(t)
+	content := make([]byte, 2*testSegmentSize+5)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatal(err)
+	}
+	stored := encryptTestContent(t, e, recorder, content)
+	header, headerSize, err := parseEncryptedHeader(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := e.dataCipher(ctx, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trailer := stored[len(stored)-encryptedTrailerSize:]
+
+	// Reseal the segments with the data key, flagging the given one as last
+	reseal := func(lastSegment uint32) []byte {
+		data := append([]byte(nil), stored[:headerSize]...)
+		for counter := uint32(0); counter < 3; counter++ {
+			plain := content[counter*testSegmentSize : min(len(content), int(counter+1)*testSegmentSize)]
+			var additional []byte
+			if counter == 2 {
+				additional = trailer
+			}
+			data = aead.Seal(data, segmentNonce(header.NoncePrefix, counter, counter == lastSegment), plain, additional)
+		}
+		return append(data, trailer...)
+	}
+
+	// Resealed unchanged decrypts, so the failures below are the flag
+	if got, err := e.decrypt(ctx, reseal(2)); err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("unchanged: %v", err)
+	}
+	for name, lastSegment := range map[string]uint32{"final not flagged": 3, "first flagged": 0} {
+		if _, err := e.decrypt(ctx, reseal(lastSegment)); !errors.Is(err, ErrEncryptedDecrypt) {
+			t.Errorf("%s: got %v, want %v", name, err, ErrEncryptedDecrypt)
+		}
+	}
+}
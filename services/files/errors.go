@@ -0,0 +1,15 @@
+package files
+
+import "go.microcore.dev/framework/errors"
+
+var (
+	// Tree
+	ErrTreeCopyIntoSelf = errors.New(errors.ErrBadRequest, "copy_into_self")
+	// Encryption
+	ErrEncryptedInvalidHeader = errors.New(errors.ErrBadRequest, "invalid_encryption_header")
+	ErrEncryptedDecrypt       = errors.New(errors.ErrBadRequest, "decryption_failed")
+	ErrEncryptedShareLink     = errors.New(errors.ErrBadRequest, "encrypted_share_link")
+	// Images
+	ErrImageDecode   = errors.New(errors.ErrBadRequest, "invalid_image")
+	ErrImageTooLarge = errors.New(errors.ErrBadRequest, "image_too_large")
+)
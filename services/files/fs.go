@@ -0,0 +1,309 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+type FSConfig struct {
+	Context   context.Context
+	Files     adapter.Interface
+	AuthToken string
+	// Remote dir mounted as the FS root
+	Root string
+}
+
+// NewFS returns an fs.FS backed by the files service. It also implements
+// fs.ReadDirFS, fs.ReadFileFS, fs.StatFS and WritableFS.
+func NewFS(config *FSConfig) *FS {
+	ctx := config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &FS{
+		ctx,
+		config.Files,
+		config.AuthToken,
+		config.Root,
+	}
+}
+
+type FS struct {
+	ctx       context.Context
+	files     adapter.Interface
+	authToken string
+	root      string
+}
+
+var (
+	_ fs.FS         = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ WritableFS    = (*FS)(nil)
+)
+
+func (f *FS) Open(name string) (fs.File, error) {
+	info, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dirs
+	if info.IsDir() {
+		return &dir{fs: f, name: name, info: info}, nil
+	}
+
+	// Files
+	size := info.Size()
+	reader, err := adapter.NewFileReader(f.ctx, f.files, f.authToken, f.remote(name), &adapter.FileReaderConfig{
+		Size: &size,
+	})
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	return &file{info: info, reader: reader}, nil
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	return f.stat("stat", name)
+}
+
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	list, err := f.files.ListFiles(f.ctx, f.authToken, f.remote(name))
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+
+	entries := make([]fs.DirEntry, 0, len(list))
+	for _, item := range list {
+		entries = append(entries, fs.FileInfoToDirEntry(newFileInfo(item)))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	data, err := f.files.GetFile(f.ctx, f.authToken, f.remote(name))
+	if err != nil {
+		return nil, pathError("readfile", name, err)
+	}
+
+	return data, nil
+}
+
+// Helper for lookup file info
+func (f *FS) stat(op string, name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	// Root is always a dir
+	if name == "." {
+		return newFileInfo(adapter.FileResult{Name: ".", IsDir: true}), nil
+	}
+
+	stat, err := f.files.StatFile(f.ctx, f.authToken, f.remote(name))
+	if err != nil {
+		return nil, pathError(op, name, err)
+	}
+
+	return newStatFileInfo(stat), nil
+}
+
+// Helper for map FS name to service path
+func (f *FS) remote(name string) string {
+	if name == "." {
+		return f.root
+	}
+	if f.root == "" {
+		return name
+	}
+	return path.Join(f.root, name)
+}
+
+// Helper for map adapter errors to fs errors
+func pathError(op string, name string, err error) error {
+	switch {
+	case errors.Is(err, adapter.ErrFileNotFound),
+		errors.Is(err, adapter.ErrFileOldNotFound),
+		errors.Is(err, adapter.ErrDirNotFound),
+		errors.Is(err, adapter.ErrDirOldNotFound),
+		errors.Is(err, adapter.ErrDirInvalidPath):
+		err = fs.ErrNotExist
+	case errors.Is(err, adapter.ErrFileExist),
+		errors.Is(err, adapter.ErrFileNewExist),
+		errors.Is(err, adapter.ErrDirExist),
+		errors.Is(err, adapter.ErrDirNewExist):
+		err = fs.ErrExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// File info
+
+type fileInfo struct {
+	result  adapter.FileResult
+	modTime time.Time
+	sys     any
+}
+
+func newFileInfo(result adapter.FileResult) *fileInfo {
+	return &fileInfo{result, time.Time{}, result}
+}
+
+func newStatFileInfo(stat *adapter.StatFileResult) *fileInfo {
+	result := adapter.FileResult{
+		Name:     stat.Name,
+		IsDir:    stat.IsDir,
+		Size:     stat.Size,
+		MimeType: stat.MimeType,
+	}
+	return &fileInfo{result, stat.Updated, stat}
+}
+
+func (i *fileInfo) Name() string {
+	return i.result.Name
+}
+
+func (i *fileInfo) Size() int64 {
+	if i.result.Size == nil {
+		return 0
+	}
+	return *i.result.Size
+}
+
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.result.IsDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (i *fileInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+func (i *fileInfo) IsDir() bool {
+	return i.result.IsDir
+}
+
+// Sys returns the underlying *adapter.StatFileResult for stat results
+// and adapter.FileResult for dir entries
+func (i *fileInfo) Sys() any {
+	return i.sys
+}
+
+// MimeType returns the file MIME type reported by the service
+func (i *fileInfo) MimeType() string {
+	if i.result.MimeType == nil {
+		return ""
+	}
+	return *i.result.MimeType
+}
+
+// Files
+
+type file struct {
+	info   fs.FileInfo
+	reader *adapter.FileReader
+	closed bool
+}
+
+func (f *file) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.info.Name(), Err: fs.ErrClosed}
+	}
+	return f.reader.Read(p)
+}
+
+func (f *file) ReadAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.info.Name(), Err: fs.ErrClosed}
+	}
+	return f.reader.ReadAt(p, off)
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.info.Name(), Err: fs.ErrClosed}
+	}
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *file) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.info.Name(), Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+// Dirs
+
+type dir struct {
+	fs      *FS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	loaded  bool
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *dir) Read(p []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errIsDir}
+}
+
+func (d *dir) Close() error {
+	return nil
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.loaded {
+		entries, err := d.fs.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.loaded = true
+	}
+
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
@@ -0,0 +1,295 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+const (
+	// Default JPEG quality of derivatives.
+	ImagesDefaultQuality = 85
+	// Default max decoded pixels of an original, protects against
+	// decompression bombs.
+	ImagesDefaultMaxPixels = 50_000_000
+)
+
+type ImageFit int
+
+const (
+	// Scale to fit within the size keeping the aspect ratio
+	ImageFitContain ImageFit = iota
+	// Scale and center crop to exactly the size
+	ImageFitCover
+	// Stretch to exactly the size
+	ImageFitFill
+)
+
+// ImageDerivative is a generated image size. A zero Width or Height is
+// derived from the aspect ratio of the original. Originals are never
+// upscaled by ImageFitContain.
+type ImageDerivative struct {
+	Width  int
+	Height int
+	Fit    ImageFit
+	// JPEG quality 1-100, ImagesDefaultQuality if zero
+	Quality int
+}
+
+type ImagesConfig struct {
+	Files       adapter.Interface
+	Derivatives []ImageDerivative
+	// Max decoded pixels of an original, ImagesDefaultMaxPixels if zero
+	MaxPixels int
+}
+
+// NewImages returns a files adapter wrapper that generates the configured
+// derivatives of uploaded JPEG, PNG and GIF images (first frame only) and
+// uploads them next to the original, see ImageDerivativePath. Other
+// uploads are passed through.
+func NewImages(config *ImagesConfig) *Images {
+	maxPixels := config.MaxPixels
+	if maxPixels <= 0 {
+		maxPixels = ImagesDefaultMaxPixels
+	}
+	return &Images{
+		config.Files,
+		config.Derivatives,
+		maxPixels,
+	}
+}
+
+type Images struct {
+	adapter.Interface
+	derivatives []ImageDerivative
+	maxPixels   int
+}
+
+// ImageDerivativePath returns the path of the width x height derivative of
+// the file path, for example "/avatars/1.jpg" -> "/avatars/1_128x128.jpg".
+func ImageDerivativePath(filePath string, width int, height int) string {
+	ext := path.Ext(filePath)
+	var name strings.Builder
+	name.WriteString(strings.TrimSuffix(filePath, ext))
+	name.WriteString("_")
+	name.WriteString(strconv.Itoa(width))
+	name.WriteString("x")
+	name.WriteString(strconv.Itoa(height))
+	name.WriteString(ext)
+	return name.String()
+}
+
+// DerivativePath returns the path of the smallest configured derivative
+// covering width x height, or the file path itself if none does.
+// Derivatives with a zero Width or Height are skipped, their size depends
+// on the original.
+func (i *Images) DerivativePath(filePath string, width int, height int) string {
+	var best *ImageDerivative
+	for n := range i.derivatives {
+		d := &i.derivatives[n]
+		if d.Width == 0 || d.Height == 0 || d.Width < width || d.Height < height {
+			continue
+		}
+		if best == nil || max(d.Width, d.Height) < max(best.Width, best.Height) {
+			best = d
+		}
+	}
+	if best == nil {
+		return filePath
+	}
+	return ImageDerivativePath(filePath, best.Width, best.Height)
+}
+
+func (i *Images) CreateFile(ctx context.Context, authToken string, data adapter.CreateFileData) error {
+	content, err := io.ReadAll(data.File)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	// Decode before upload to reject broken images
+	var src image.Image
+	var format string
+	if len(i.derivatives) > 0 {
+		src, format, err = i.decode(content)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Original
+	data.File = bytes.NewReader(content)
+	if err := i.Interface.CreateFile(ctx, authToken, data); err != nil {
+		return err
+	}
+	if src == nil {
+		return nil
+	}
+
+	// Derivatives
+	for _, derivative := range i.derivatives {
+		var buf bytes.Buffer
+		if err := encodeImage(&buf, resizeImage(src, derivative), format, derivative.Quality); err != nil {
+			return fmt.Errorf("encode %dx%d: %w", derivative.Width, derivative.Height, err)
+		}
+		name := path.Base(ImageDerivativePath(data.Name, derivative.Width, derivative.Height))
+		if err := i.upload(ctx, authToken, data.Path, name, buf.Bytes()); err != nil {
+			return fmt.Errorf("upload %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// UploadArchive unpacks an archive and generates the derivatives of its
+// images.
+func (i *Images) UploadArchive(ctx context.Context, authToken string, data adapter.UploadArchiveData) (*adapter.UploadArchiveResult, error) {
+	return adapter.ExtractArchive(ctx, i, authToken, data)
+}
+
+// Helper for decode an image, a nil image means not a supported image
+func (i *Images) decode(content []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrImageDecode, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", ErrImageDecode
+	}
+	if config.Width*config.Height > i.maxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrImageDecode, err)
+	}
+
+	return img, format, nil
+}
+
+// Helper for upload a derivative, replacing a stale one
+func (i *Images) upload(ctx context.Context, authToken string, dir string, name string, content []byte) error {
+	data := adapter.CreateFileData{
+		Path: dir,
+		File: bytes.NewReader(content),
+		Name: name,
+	}
+	err := i.Interface.CreateFile(ctx, authToken, data)
+	if errors.Is(err, adapter.ErrFileExist) {
+		data.File = bytes.NewReader(content)
+		err = adapter.ReplaceFile(ctx, i.Interface, authToken, data)
+	}
+	return err
+}
+
+// Helper for encode an image in the format of the original
+func encodeImage(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case "jpeg":
+		if quality <= 0 || quality > 100 {
+			quality = ImagesDefaultQuality
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case "gif":
+		return gif.Encode(w, img, nil)
+	default:
+		return png.Encode(w, img)
+	}
+}
+
+// Helper for resize an image according to the derivative fit
+func resizeImage(src image.Image, derivative ImageDerivative) image.Image {
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	width, height := derivative.Width, derivative.Height
+
+	// Derive a zero dimension from the aspect ratio
+	switch {
+	case width <= 0 && height <= 0:
+		width, height = sw, sh
+	case width <= 0:
+		width = max(1, sw*height/sh)
+	case height <= 0:
+		height = max(1, sh*width/sw)
+	}
+
+	switch derivative.Fit {
+	case ImageFitCover:
+		// Center crop to the target aspect ratio
+		cw, ch := sw, sh
+		if sw*height > sh*width {
+			cw = max(1, sh*width/height)
+		} else {
+			ch = max(1, sw*height/width)
+		}
+		x0 := bounds.Min.X + (sw-cw)/2
+		y0 := bounds.Min.Y + (sh-ch)/2
+		return scaleImage(src, image.Rect(x0, y0, x0+cw, y0+ch), width, height)
+	case ImageFitFill:
+		return scaleImage(src, bounds, width, height)
+	default:
+		// Fit within, never upscale
+		if sw*height > sh*width {
+			height = max(1, sh*width/sw)
+		} else {
+			width = max(1, sw*height/sh)
+		}
+		if width >= sw || height >= sh {
+			width, height = sw, sh
+		}
+		return scaleImage(src, bounds, width, height)
+	}
+}
+
+// Helper for scale the rect of an image to width x height by area averaging
+func scaleImage(src image.Image, rect image.Rectangle, width int, height int) *image.RGBA {
+	// Premultiplied RGBA source
+	rgba := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, rect.Min, draw.Src)
+	sw, sh := rgba.Rect.Dx(), rgba.Rect.Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		sy0 := y * sh / height
+		sy1 := max(sy0+1, (y+1)*sh/height)
+		for x := range width {
+			sx0 := x * sw / width
+			sx1 := max(sx0+1, (x+1)*sw/width)
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride+sx0*4 : sy*rgba.Stride+sx1*4]
+				for p := 0; p < len(row); p += 4 {
+					r += uint64(row[p])
+					g += uint64(row[p+1])
+					b += uint64(row[p+2])
+					a += uint64(row[p+3])
+					n++
+				}
+			}
+
+			o := y*dst.Stride + x*4
+			dst.Pix[o] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(b / n)
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
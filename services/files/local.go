@@ -0,0 +1,82 @@
+package files
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// NewLocalFS returns a WritableFS rooted at the local dir.
+func NewLocalFS(root string) *LocalFS {
+	return &LocalFS{
+		root,
+		os.DirFS(root),
+	}
+}
+
+type LocalFS struct {
+	root string
+	fsys fs.FS
+}
+
+var (
+	_ fs.ReadDirFS  = (*LocalFS)(nil)
+	_ fs.ReadFileFS = (*LocalFS)(nil)
+	_ WritableFS    = (*LocalFS)(nil)
+)
+
+func (l *LocalFS) Open(name string) (fs.File, error) {
+	return l.fsys.Open(name)
+}
+
+func (l *LocalFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(l.fsys, name)
+}
+
+func (l *LocalFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(l.fsys, name)
+}
+
+func (l *LocalFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(l.fsys, name)
+}
+
+func (l *LocalFS) Create(name string) (io.WriteCloser, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.Create(l.local(name))
+}
+
+func (l *LocalFS) MkdirAll(name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.MkdirAll(l.local(name), 0755)
+}
+
+func (l *LocalFS) Rename(oldName string, newName string) error {
+	if !fs.ValidPath(oldName) || !fs.ValidPath(newName) || oldName == "." || newName == "." {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrInvalid}
+	}
+
+	// Keep service semantics, never replace an existing target
+	if _, err := os.Lstat(l.local(newName)); err == nil {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrExist}
+	}
+
+	return os.Rename(l.local(oldName), l.local(newName))
+}
+
+func (l *LocalFS) RemoveAll(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "removeall", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.RemoveAll(l.local(name))
+}
+
+// Helper for map FS name to local path
+func (l *LocalFS) local(name string) string {
+	return filepath.Join(l.root, filepath.FromSlash(name))
+}
@@ -0,0 +1,370 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewMemFS returns an empty in-memory WritableFS, safe for concurrent use.
+func NewMemFS() *MemFS {
+	return &MemFS{
+		files: map[string]*memNode{
+			".": {isDir: true, modTime: time.Now()},
+		},
+	}
+}
+
+type MemFS struct {
+	mu    sync.RWMutex
+	files map[string]*memNode
+}
+
+var (
+	_ fs.ReadDirFS  = (*MemFS)(nil)
+	_ fs.ReadFileFS = (*MemFS)(nil)
+	_ WritableFS    = (*MemFS)(nil)
+)
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	info := &memInfo{name: path.Base(name), node: node}
+	if node.isDir {
+		return &memDir{name: name, info: info, entries: m.entries(name)}, nil
+	}
+	return &memFile{name: name, info: info, reader: bytes.NewReader(node.data)}, nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return &memInfo{name: path.Base(name), node: node}, nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !node.isDir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+	return m.entries(name), nil
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, err := m.lookup("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.isDir {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errIsDir}
+	}
+	return slices.Clone(node.data), nil
+}
+
+func (m *MemFS) Create(name string) (io.WriteCloser, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// Check parent dir
+	parent := m.files[path.Dir(name)]
+	if parent == nil {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrNotExist}
+	}
+	if !parent.isDir {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: errNotDir}
+	}
+	if node := m.files[name]; node != nil && node.isDir {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: errIsDir}
+	}
+
+	return &memWriter{fs: m, name: name}, nil
+}
+
+func (m *MemFS) MkdirAll(name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var current string
+	for _, elem := range strings.Split(name, "/") {
+		current = path.Join(current, elem)
+		node := m.files[current]
+		if node == nil {
+			m.files[current] = &memNode{isDir: true, modTime: time.Now()}
+			continue
+		}
+		if !node.isDir {
+			return &fs.PathError{Op: "mkdir", Path: current, Err: errNotDir}
+		}
+	}
+
+	return nil
+}
+
+func (m *MemFS) Rename(oldName string, newName string) error {
+	if !fs.ValidPath(oldName) || !fs.ValidPath(newName) || oldName == "." || newName == "." {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrInvalid}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.files[oldName] == nil {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrNotExist}
+	}
+	if m.files[newName] != nil {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrExist}
+	}
+	if parent := m.files[path.Dir(newName)]; parent == nil || !parent.isDir {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrNotExist}
+	}
+	if strings.HasPrefix(newName, oldName+"/") {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrInvalid}
+	}
+
+	for name, file := range m.files {
+		switch {
+		case name == oldName:
+			delete(m.files, name)
+			m.files[newName] = file
+		case strings.HasPrefix(name, oldName+"/"):
+			delete(m.files, name)
+			m.files[newName+strings.TrimPrefix(name, oldName)] = file
+		}
+	}
+
+	return nil
+}
+
+func (m *MemFS) RemoveAll(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "removeall", Path: name, Err: fs.ErrInvalid}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for file := range m.files {
+		if file == name || strings.HasPrefix(file, name+"/") {
+			delete(m.files, file)
+		}
+	}
+
+	return nil
+}
+
+// Helper for lookup a node, the caller holds the lock
+func (m *MemFS) lookup(op string, name string) (*memNode, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	node := m.files[name]
+	if node == nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return node, nil
+}
+
+// Helper for list the entries of a dir sorted by name, the caller holds
+// the lock
+func (m *MemFS) entries(dir string) []fs.DirEntry {
+	var entries []fs.DirEntry
+	for name, node := range m.files {
+		if name != "." && path.Dir(name) == dir {
+			entries = append(entries, fs.FileInfoToDirEntry(&memInfo{name: path.Base(name), node: node}))
+		}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries
+}
+
+var (
+	errNotDir = errors.New("not a directory")
+	errIsDir  = errors.New("is a directory")
+)
+
+// Mem writer
+
+type memWriter struct {
+	fs     *MemFS
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, &fs.PathError{Op: "write", Path: w.name, Err: fs.ErrClosed}
+	}
+	return w.buf.Write(p)
+}
+
+func (w *memWriter) Close() error {
+	if w.closed {
+		return &fs.PathError{Op: "close", Path: w.name, Err: fs.ErrClosed}
+	}
+	w.closed = true
+
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+	w.fs.files[w.name] = &memNode{
+		data:    w.buf.Bytes(),
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+// Mem nodes, the data of a file is not modified after Close
+
+type memNode struct {
+	data    []byte
+	isDir   bool
+	modTime time.Time
+}
+
+type memInfo struct {
+	name string
+	node *memNode
+}
+
+func (i *memInfo) Name() string {
+	return i.name
+}
+
+func (i *memInfo) Size() int64 {
+	return int64(len(i.node.data))
+}
+
+func (i *memInfo) Mode() fs.FileMode {
+	if i.node.isDir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (i *memInfo) ModTime() time.Time {
+	return i.node.modTime
+}
+
+func (i *memInfo) IsDir() bool {
+	return i.node.isDir
+}
+
+func (i *memInfo) Sys() any {
+	return nil
+}
+
+// Mem files
+
+type memFile struct {
+	name   string
+	info   fs.FileInfo
+	reader *bytes.Reader
+	closed bool
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.reader.Read(p)
+}
+
+func (f *memFile) ReadAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.reader.ReadAt(p, off)
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *memFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+// Mem dirs
+
+type memDir struct {
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *memDir) Read(p []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errIsDir}
+}
+
+func (d *memDir) Close() error {
+	return nil
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
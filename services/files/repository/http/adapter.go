@@ -1,14 +1,20 @@
 package adapter
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
+	"net/textproto"
+	neturl "net/url"
+	"strconv"
 	"strings"
+	"sync"
 
 	"go.microcore.dev/framework/transport/http"
 	"go.microcore.dev/framework/transport/http/client"
@@ -17,18 +23,43 @@ import (
 type Config struct {
 	HttpClientManager    client.Manager
 	FilesServiceEndpoint string
+	// Public endpoint used in share link urls, FilesServiceEndpoint if empty
+	FilesPublicEndpoint string
+	// Send MD5 and CRC32C upload checksums in addition to SHA-256
+	ChecksumMd5    bool
+	ChecksumCrc32c bool
+	// Validate uploads before sending, optional
+	UploadPolicy *UploadPolicy
 }
 
 func New(config *Config) Interface {
+	publicEndpoint := config.FilesPublicEndpoint
+	if publicEndpoint == "" {
+		publicEndpoint = config.FilesServiceEndpoint
+	}
+
 	return &adapter{
-		config.HttpClientManager,
-		config.FilesServiceEndpoint,
+		httpClientManager:    config.HttpClientManager,
+		filesServiceEndpoint: config.FilesServiceEndpoint,
+		filesPublicEndpoint:  publicEndpoint,
+		checksumMd5:          config.ChecksumMd5,
+		checksumCrc32c:       config.ChecksumCrc32c,
+		uploadPolicy:         config.UploadPolicy,
+		checksums:            make(map[string]string),
 	}
 }
 
 type adapter struct {
 	httpClientManager    client.Manager
 	filesServiceEndpoint string
+	filesPublicEndpoint  string
+	checksumMd5          bool
+	checksumCrc32c       bool
+	uploadPolicy         *UploadPolicy
+
+	// Checksums of download tokens, verified by StreamFile
+	mu        sync.Mutex
+	checksums map[string]string
 }
 
 // Dirs
@@ -167,6 +198,110 @@ func (a *adapter) DeleteDir(ctx context.Context, authToken string, path string)
 	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
+func (a *adapter) CopyDir(ctx context.Context, authToken string, data CopyDirData) error {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/dir/copy")
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 201 {
+		return nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_src_path":  ErrDirInvalidSrcPath,
+		"bad_request:invalid_dst_path":  ErrDirInvalidDstPath,
+		"bad_request:invalid_overwrite": ErrInvalidOverwrite,
+		"bad_request:src_dir_not_found": ErrDirSrcNotFound,
+		"bad_request:dst_dir_exist":     ErrDirDstExist,
+		"bad_request:dst_file_exist":    ErrFileDstExist,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+func (a *adapter) MoveDir(ctx context.Context, authToken string, data MoveDirData) error {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/dir/move")
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 204 {
+		return nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_src_path":  ErrDirInvalidSrcPath,
+		"bad_request:invalid_dst_path":  ErrDirInvalidDstPath,
+		"bad_request:invalid_overwrite": ErrInvalidOverwrite,
+		"bad_request:src_dir_not_found": ErrDirSrcNotFound,
+		"bad_request:dst_dir_exist":     ErrDirDstExist,
+		"bad_request:dst_file_exist":    ErrFileDstExist,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
 // Files
 
 func (a *adapter) GetFile(ctx context.Context, authToken string, path string) ([]byte, error) {
@@ -175,34 +310,113 @@ func (a *adapter) GetFile(ctx context.Context, authToken string, path string) ([
 		return nil, err
 	}
 
-	stream, err := a.StreamFile(ctx, download.Token)
+	return a.StreamFile(ctx, download.Token)
+}
+
+func (a *adapter) StreamFile(ctx context.Context, token string) ([]byte, error) {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/download/stream/")
+	url.WriteString(token)
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodGet),
+		client.WithRequestContext(ctx),
+	)
 	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	checksum, hasChecksum := a.takeChecksum(token)
+	if res.StatusCode() == 200 {
+		// Verify content if the service reported a checksum
+		if hasChecksum {
+			if err := VerifyChecksum(res.Body(), checksum); err != nil {
+				return nil, err
+			}
+		}
+		return res.Body(), nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_token": ErrFileInvalidToken,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
 		return nil, err
 	}
 
-	return stream, nil
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
-func (a *adapter) StreamFile(ctx context.Context, token string) ([]byte, error) {
+func (a *adapter) StreamFileRange(ctx context.Context, token string, offset int64, length int64) ([]byte, error) {
+	// Check range
+	if offset < 0 || length < 0 {
+		return nil, ErrFileInvalidRange
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.filesServiceEndpoint)
 	url.WriteString("/files/download/stream/")
 	url.WriteString(token)
 
+	// Build range header, zero length means up to the end of file
+	var byteRange strings.Builder
+	byteRange.WriteString("bytes=")
+	byteRange.WriteString(strconv.FormatInt(offset, 10))
+	byteRange.WriteString("-")
+	if length > 0 {
+		byteRange.WriteString(strconv.FormatInt(offset+length-1, 10))
+	}
+
+	// Tokens are single use, a range is not verified
+	a.takeChecksum(token)
+
 	// Send service request
 	res, err := a.httpClientManager.Request(
 		url.String(),
 		client.WithRequestMethod(http.MethodGet),
 		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Range", byteRange.String()),
+		),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
 	}
 
 	// Check success status code
-	if res.StatusCode() == 200 {
-		return res.Body(), nil
+	switch res.StatusCode() {
+	case 206:
+		// A range past the end of file is cut short, never longer
+		body := res.Body()
+		if length > 0 && int64(len(body)) > length {
+			return nil, ErrFileInvalidRange
+		}
+		return body, nil
+	case 200:
+		// Range ignored by service, cut the requested part from the full body
+		body := res.Body()
+		if offset > int64(len(body)) {
+			return nil, ErrFileInvalidRange
+		}
+		end := int64(len(body))
+		if length > 0 && offset+length < end {
+			end = offset + length
+		}
+		return body[offset:end], nil
+	case 416:
+		return nil, ErrFileInvalidRange
 	}
 
 	// Response message
@@ -211,6 +425,7 @@ func (a *adapter) StreamFile(ctx context.Context, token string) ([]byte, error)
 	// Errors map
 	var errMap = map[string]error{
 		"bad_request:invalid_token": ErrFileInvalidToken,
+		"bad_request:invalid_range": ErrFileInvalidRange,
 	}
 
 	// Parse errors
@@ -247,6 +462,9 @@ func (a *adapter) DownloadFile(ctx context.Context, authToken string, path strin
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
 		}
+		if response.Checksum != nil {
+			a.putChecksum(response.Token, *response.Checksum)
+		}
 		return &response, nil
 	}
 
@@ -312,6 +530,52 @@ func (a *adapter) ListFiles(ctx context.Context, authToken string, path string)
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
+func (a *adapter) StatFile(ctx context.Context, authToken string, path string) (*StatFileResult, error) {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/stat/")
+	url.WriteString(base64.RawURLEncoding.EncodeToString([]byte(path)))
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodGet),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 200 {
+		var response StatFileResult
+		if err := json.Unmarshal(res.Body(), &response); err != nil {
+			return nil, fmt.Errorf("error parsing response body: %v", err)
+		}
+		return &response, nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_path":   ErrDirInvalidPath,
+		"bad_request:file_not_found": ErrFileNotFound,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
 func (a *adapter) CreateFile(ctx context.Context, authToken string, data CreateFileData) error {
 	// Build url
 	var url strings.Builder
@@ -319,21 +583,52 @@ func (a *adapter) CreateFile(ctx context.Context, authToken string, data CreateF
 	url.WriteString("/files/")
 	url.WriteString(base64.RawURLEncoding.EncodeToString([]byte(data.Path)))
 
+	// Read file start for content type detection, up to one byte over
+	// the policy max size
+	file := data.File
+	if a.uploadPolicy != nil && a.uploadPolicy.MaxSize > 0 {
+		file = io.LimitReader(file, a.uploadPolicy.MaxSize+1)
+	}
+	reader := bufio.NewReaderSize(file, sniffLen)
+	head, err := reader.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("read file: %w", err)
+	}
+	contentType := DetectContentType(data.Name, head)
+
 	// Multipart body
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
 
-	// Create form file
-	part, err := writer.CreateFormFile("file", data.Name)
+	// Create form file with detected content type
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     "file",
+		"filename": data.Name,
+	}))
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
 	if err != nil {
 		return fmt.Errorf("create form file: %w", err)
 	}
 
-	// Copy file content
-	if _, err := io.Copy(part, data.File); err != nil {
+	// Copy file content, hashed while copying
+	checksums := a.uploadChecksums()
+	size, err := io.Copy(part, io.TeeReader(reader, checksums))
+	if err != nil {
 		return fmt.Errorf("copy file: %w", err)
 	}
 
+	// Validate upload
+	if err := a.uploadPolicy.validate(data.Name, contentType, size); err != nil {
+		return err
+	}
+
+	// Checksum fields after the file content
+	if err := checksums.writeFields(writer); err != nil {
+		return fmt.Errorf("write checksums: %w", err)
+	}
+
 	// Close multipart writer
 	if err := writer.Close(); err != nil {
 		return fmt.Errorf("close writer: %w", err)
@@ -364,8 +659,9 @@ func (a *adapter) CreateFile(ctx context.Context, authToken string, data CreateF
 
 	// Errors map
 	var errMap = map[string]error{
-		"bad_request:dir_not_found": ErrDirNotFound,
-		"bad_request:file_exist":    ErrFileExist,
+		"bad_request:dir_not_found":     ErrDirNotFound,
+		"bad_request:file_exist":        ErrFileExist,
+		"bad_request:checksum_mismatch": ErrChecksumMismatch,
 	}
 
 	// Parse errors
@@ -467,3 +763,276 @@ func (a *adapter) DeleteFile(ctx context.Context, authToken string, path string)
 
 	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
+
+func (a *adapter) CopyFile(ctx context.Context, authToken string, data CopyFileData) error {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/copy")
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 201 {
+		return nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_src_path":   ErrDirInvalidSrcPath,
+		"bad_request:invalid_dst_path":   ErrDirInvalidDstPath,
+		"bad_request:invalid_overwrite":  ErrInvalidOverwrite,
+		"bad_request:src_file_not_found": ErrFileSrcNotFound,
+		"bad_request:dir_not_found":      ErrDirNotFound,
+		"bad_request:dst_file_exist":     ErrFileDstExist,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+func (a *adapter) MoveFile(ctx context.Context, authToken string, data MoveFileData) error {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/move")
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 204 {
+		return nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_src_path":   ErrDirInvalidSrcPath,
+		"bad_request:invalid_dst_path":   ErrDirInvalidDstPath,
+		"bad_request:invalid_overwrite":  ErrInvalidOverwrite,
+		"bad_request:src_file_not_found": ErrFileSrcNotFound,
+		"bad_request:dir_not_found":      ErrDirNotFound,
+		"bad_request:dst_file_exist":     ErrFileDstExist,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+// Archives
+
+// DownloadArchive returns a zip or tar(.gz) stream of the files and dirs
+// at paths, see NewArchiveReader.
+func (a *adapter) DownloadArchive(ctx context.Context, authToken string, paths []string, format ArchiveFormat) (io.ReadCloser, error) {
+	return NewArchiveReader(ctx, a, authToken, paths, format)
+}
+
+// UploadArchive unpacks a zip or tar(.gz) archive into a dir, see
+// ExtractArchive.
+func (a *adapter) UploadArchive(ctx context.Context, authToken string, data UploadArchiveData) (*UploadArchiveResult, error) {
+	return ExtractArchive(ctx, a, authToken, data)
+}
+
+// Share links
+
+func (a *adapter) CreateShareLink(ctx context.Context, authToken string, path string, data CreateShareLinkData) (*ShareLinkResult, error) {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/share/")
+	url.WriteString(base64.RawURLEncoding.EncodeToString([]byte(path)))
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 201 {
+		var response ShareLinkResult
+		if err := json.Unmarshal(res.Body(), &response); err != nil {
+			return nil, fmt.Errorf("error parsing response body: %v", err)
+		}
+		return &response, nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_path":          ErrDirInvalidPath,
+		"bad_request:file_not_found":        ErrFileNotFound,
+		"bad_request:invalid_expires":       ErrShareLinkInvalidExpires,
+		"bad_request:invalid_max_downloads": ErrShareLinkInvalidMaxDownloads,
+		"bad_request:invalid_filename":      ErrShareLinkInvalidFilename,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+func (a *adapter) ListShareLinks(ctx context.Context, authToken string, path string) ([]ShareLinkResult, error) {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/share/list/")
+	url.WriteString(base64.RawURLEncoding.EncodeToString([]byte(path)))
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodGet),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 200 {
+		var response []ShareLinkResult
+		if err := json.Unmarshal(res.Body(), &response); err != nil {
+			return nil, fmt.Errorf("error parsing response body: %v", err)
+		}
+		return response, nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_path": ErrDirInvalidPath,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+func (a *adapter) RevokeShareLink(ctx context.Context, authToken string, id string) error {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesServiceEndpoint)
+	url.WriteString("/files/share/")
+	url.WriteString(neturl.PathEscape(id))
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodDelete),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("service %s unavailable: %v", a.filesServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 204 {
+		return nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:share_link_not_found": ErrShareLinkNotFound,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+// ShareLinkUrl returns the public download url of a share link token
+func (a *adapter) ShareLinkUrl(token string) string {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.filesPublicEndpoint)
+	url.WriteString("/files/share/stream/")
+	url.WriteString(neturl.PathEscape(token))
+
+	return url.String()
+}
@@ -0,0 +1,258 @@
+package adapter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+type ArchiveFormat string
+
+const (
+	ArchiveFormatZip   ArchiveFormat = "zip"
+	ArchiveFormatTar   ArchiveFormat = "tar"
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+)
+
+func (f ArchiveFormat) Valid() bool {
+	switch f {
+	case ArchiveFormatZip, ArchiveFormatTar, ArchiveFormatTarGz:
+		return true
+	}
+	return false
+}
+
+// NewArchiveReader returns a stream of an archive of the files and dirs
+// at paths, built on the fly with ListFiles and StreamFile. Entries are
+// named relative to the parent of their path, so dirs keep their own name.
+// One file is held in memory at a time. Closing the reader cancels the
+// build, errors during the build are returned by Read.
+func NewArchiveReader(ctx context.Context, files Interface, authToken string, paths []string, format ArchiveFormat) (io.ReadCloser, error) {
+	if !format.Valid() {
+		return nil, ErrInvalidArchiveFormat
+	}
+	if len(paths) == 0 {
+		return nil, ErrDirInvalidPath
+	}
+
+	// Resolve roots before streaming to report missing paths
+	roots := make([]archiveRoot, 0, len(paths))
+	for _, p := range paths {
+		p = path.Clean("/" + p)
+		root := archiveRoot{path: p, isDir: true}
+		if p != "/" {
+			stat, err := files.StatFile(ctx, authToken, p)
+			if err != nil {
+				return nil, err
+			}
+			root.name = stat.Name
+			root.isDir = stat.IsDir
+		}
+		roots = append(roots, root)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	w := &archiveWriter{ctx, files, authToken, time.Now()}
+
+	go func() {
+		defer cancel()
+		pw.CloseWithError(w.write(pw, roots, format))
+	}()
+
+	return &archiveReader{pr, cancel}, nil
+}
+
+type archiveRoot struct {
+	path  string
+	name  string
+	isDir bool
+}
+
+// Archive reader
+
+type archiveReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *archiveReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+// Archive writer
+
+type archiveWriter struct {
+	ctx       context.Context
+	files     Interface
+	authToken string
+	modTime   time.Time
+}
+
+// Archive entry sink of a single format
+type archiveSink interface {
+	dir(name string) error
+	file(name string, data []byte) error
+	close() error
+}
+
+func (w *archiveWriter) write(out io.Writer, roots []archiveRoot, format ArchiveFormat) error {
+	var sink archiveSink
+	switch format {
+	case ArchiveFormatZip:
+		sink = &zipSink{zip.NewWriter(out), w.modTime}
+	case ArchiveFormatTar:
+		sink = &tarSink{tar.NewWriter(out), nil, w.modTime}
+	case ArchiveFormatTarGz:
+		gz := gzip.NewWriter(out)
+		sink = &tarSink{tar.NewWriter(gz), gz, w.modTime}
+	}
+
+	for _, root := range roots {
+		var err error
+		if root.isDir {
+			err = w.dir(sink, root.path, root.name)
+		} else {
+			err = w.file(sink, root.path, root.name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return sink.close()
+}
+
+func (w *archiveWriter) dir(sink archiveSink, dirPath string, name string) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	if name != "" {
+		if err := sink.dir(name); err != nil {
+			return err
+		}
+	}
+
+	list, err := w.files.ListFiles(w.ctx, w.authToken, dirPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range list {
+		entryPath, entryName := path.Join(dirPath, entry.Name), path.Join(name, entry.Name)
+		if entry.IsDir {
+			err = w.dir(sink, entryPath, entryName)
+		} else {
+			err = w.file(sink, entryPath, entryName)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *archiveWriter) file(sink archiveSink, filePath string, name string) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	download, err := w.files.DownloadFile(w.ctx, w.authToken, filePath)
+	if err != nil {
+		return err
+	}
+	data, err := w.files.StreamFile(w.ctx, download.Token)
+	if err != nil {
+		return err
+	}
+
+	// Verify content if the service reports a checksum
+	if download.Checksum != nil {
+		if err := VerifyChecksum(data, *download.Checksum); err != nil {
+			return err
+		}
+	}
+
+	return sink.file(name, data)
+}
+
+// Zip sink
+
+type zipSink struct {
+	zw      *zip.Writer
+	modTime time.Time
+}
+
+func (s *zipSink) dir(name string) error {
+	_, err := s.zw.CreateHeader(&zip.FileHeader{
+		Name:     strings.TrimSuffix(name, "/") + "/",
+		Method:   zip.Store,
+		Modified: s.modTime,
+	})
+	return err
+}
+
+func (s *zipSink) file(name string, data []byte) error {
+	fw, err := s.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: s.modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+func (s *zipSink) close() error {
+	return s.zw.Close()
+}
+
+// Tar sink
+
+type tarSink struct {
+	tw      *tar.Writer
+	gz      *gzip.Writer
+	modTime time.Time
+}
+
+func (s *tarSink) dir(name string) error {
+	return s.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     strings.TrimSuffix(name, "/") + "/",
+		Mode:     0755,
+		ModTime:  s.modTime,
+	})
+}
+
+func (s *tarSink) file(name string, data []byte) error {
+	err := s.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  s.modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.tw.Write(data)
+	return err
+}
+
+func (s *tarSink) close() error {
+	if err := s.tw.Close(); err != nil {
+		return err
+	}
+	if s.gz != nil {
+		return s.gz.Close()
+	}
+	return nil
+}
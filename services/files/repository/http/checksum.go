@@ -0,0 +1,92 @@
+package adapter
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"mime/multipart"
+	"strings"
+)
+
+// VerifyChecksum compares the SHA-256 of data with the hex encoded
+// checksum reported by the service, see DownloadFileResult.Checksum
+// and StatFileResult.Checksum.
+func VerifyChecksum(data []byte, checksum string) error {
+	sum := sha256.Sum256(data)
+	return compareChecksum(sum[:], checksum)
+}
+
+// Max cached checksums of download tokens
+const maxTokenChecksums = 1024
+
+// Upload hashes, SHA-256 is always computed, MD5 and CRC32C by config
+type uploadChecksums struct {
+	fields []string
+	hashes []hash.Hash
+}
+
+// Helper for create the upload hashes of the config
+func (a *adapter) uploadChecksums() *uploadChecksums {
+	c := &uploadChecksums{
+		fields: []string{"checksum_sha256"},
+		hashes: []hash.Hash{sha256.New()},
+	}
+	if a.checksumMd5 {
+		c.fields = append(c.fields, "checksum_md5")
+		c.hashes = append(c.hashes, md5.New())
+	}
+	if a.checksumCrc32c {
+		c.fields = append(c.fields, "checksum_crc32c")
+		c.hashes = append(c.hashes, crc32.New(crc32.MakeTable(crc32.Castagnoli)))
+	}
+	return c
+}
+
+func (c *uploadChecksums) Write(p []byte) (int, error) {
+	for _, h := range c.hashes {
+		h.Write(p)
+	}
+	return len(p), nil
+}
+
+// Helper for write the hex encoded checksums as multipart fields, they
+// follow the file so the content is hashed while it is copied
+func (c *uploadChecksums) writeFields(writer *multipart.Writer) error {
+	for i, field := range c.fields {
+		if err := writer.WriteField(field, hex.EncodeToString(c.hashes[i].Sum(nil))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Helper for keep the checksum of a download token for StreamFile
+func (a *adapter) putChecksum(token string, checksum string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.checksums) >= maxTokenChecksums {
+		clear(a.checksums)
+	}
+	a.checksums[token] = checksum
+}
+
+// Helper for take the checksum of a single use download token
+func (a *adapter) takeChecksum(token string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	checksum, ok := a.checksums[token]
+	delete(a.checksums, token)
+	return checksum, ok
+}
+
+// Helper for compare a SHA-256 sum with a hex encoded checksum
+func compareChecksum(sum []byte, checksum string) error {
+	expected, err := hex.DecodeString(strings.TrimSpace(checksum))
+	if err != nil || subtle.ConstantTimeCompare(sum, expected) != 1 {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
@@ -1,6 +1,21 @@
 package adapter
 
-import "io"
+import (
+	"io"
+	"time"
+)
+
+// Overwrite policies of copy, move and archive upload operations
+type OverwritePolicy string
+
+const (
+	// Fail with a dst exist error
+	OverwriteFail OverwritePolicy = "fail"
+	// Replace the existing file, dirs are merged
+	OverwriteReplace OverwritePolicy = "replace"
+	// Keep the existing file, dirs are merged
+	OverwriteSkip OverwritePolicy = "skip"
+)
 
 // Data
 
@@ -9,17 +24,60 @@ type RenameDirData struct {
 	NewPath string `json:"new_path"`
 }
 
+type CopyDirData struct {
+	SrcPath   string          `json:"src_path"`
+	DstPath   string          `json:"dst_path"`
+	Overwrite OverwritePolicy `json:"overwrite"`
+}
+
+type MoveDirData struct {
+	SrcPath   string          `json:"src_path"`
+	DstPath   string          `json:"dst_path"`
+	Overwrite OverwritePolicy `json:"overwrite"`
+}
+
 type CreateFileData struct {
 	Path string
 	File io.Reader
 	Name string
 }
 
+type UploadArchiveData struct {
+	// Destination dir, created if missing
+	Path   string
+	File   io.Reader
+	Format ArchiveFormat
+	// Existing files policy, OverwriteFail if empty
+	Overwrite OverwritePolicy
+	// Max total uncompressed size in bytes, ArchiveDefaultMaxSize if zero
+	MaxSize int64
+	// Max number of entries, ArchiveDefaultMaxEntries if zero
+	MaxEntries int
+}
+
 type RenameFileData struct {
 	OldPath string `json:"old_path"`
 	NewPath string `json:"new_path"`
 }
 
+type CopyFileData struct {
+	SrcPath   string          `json:"src_path"`
+	DstPath   string          `json:"dst_path"`
+	Overwrite OverwritePolicy `json:"overwrite"`
+}
+
+type MoveFileData struct {
+	SrcPath   string          `json:"src_path"`
+	DstPath   string          `json:"dst_path"`
+	Overwrite OverwritePolicy `json:"overwrite"`
+}
+
+type CreateShareLinkData struct {
+	Expires      *time.Time `json:"expires,omitempty"`
+	MaxDownloads *uint      `json:"max_downloads,omitempty"`
+	Filename     *string    `json:"filename,omitempty"`
+}
+
 // Results
 
 type FileResult struct {
@@ -30,5 +88,51 @@ type FileResult struct {
 }
 
 type DownloadFileResult struct {
-	Token string `json:"token"`
+	Token    string  `json:"token"`
+	Checksum *string `json:"checksum"`
+}
+
+type StatFileResult struct {
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     *int64    `json:"size"`
+	MimeType *string   `json:"mime_type"`
+	Checksum *string   `json:"checksum"`
+	Owner    *uint     `json:"owner"`
+	Updated  time.Time `json:"updated"`
+	Created  time.Time `json:"created"`
+}
+
+// Status of an archive entry upload
+type ArchiveEntryStatus string
+
+const (
+	ArchiveEntryCreated  ArchiveEntryStatus = "created"
+	ArchiveEntryReplaced ArchiveEntryStatus = "replaced"
+	ArchiveEntrySkipped  ArchiveEntryStatus = "skipped"
+	ArchiveEntryFailed   ArchiveEntryStatus = "failed"
+)
+
+type UploadArchiveResult struct {
+	Entries []UploadArchiveEntryResult
+}
+
+type UploadArchiveEntryResult struct {
+	Path   string
+	IsDir  bool
+	Size   int64
+	Status ArchiveEntryStatus
+	// Set for skipped and failed entries
+	Err error
+}
+
+type ShareLinkResult struct {
+	Id           string     `json:"id"`
+	Token        string     `json:"token"`
+	Path         string     `json:"path"`
+	Expires      *time.Time `json:"expires"`
+	MaxDownloads *uint      `json:"max_downloads"`
+	Downloads    uint       `json:"downloads"`
+	Filename     *string    `json:"filename"`
+	Created      time.Time  `json:"created"`
 }
@@ -11,10 +11,35 @@ var (
 	ErrDirInvalidNewPath = errors.New(errors.ErrBadRequest, "invalid_new_path")
 	ErrDirOldNotFound    = errors.New(errors.ErrBadRequest, "old_dir_not_found")
 	ErrDirNewExist       = errors.New(errors.ErrBadRequest, "new_dir_exist")
+	ErrDirInvalidSrcPath = errors.New(errors.ErrBadRequest, "invalid_src_path")
+	ErrDirInvalidDstPath = errors.New(errors.ErrBadRequest, "invalid_dst_path")
+	ErrDirSrcNotFound    = errors.New(errors.ErrBadRequest, "src_dir_not_found")
+	ErrDirDstExist       = errors.New(errors.ErrBadRequest, "dst_dir_exist")
+	ErrInvalidOverwrite  = errors.New(errors.ErrBadRequest, "invalid_overwrite")
 	// Files
 	ErrFileExist        = errors.New(errors.ErrBadRequest, "file_exist")
 	ErrFileNotFound     = errors.New(errors.ErrBadRequest, "file_not_found")
 	ErrFileOldNotFound  = errors.New(errors.ErrBadRequest, "old_file_not_found")
 	ErrFileNewExist     = errors.New(errors.ErrBadRequest, "new_file_exist")
+	ErrFileSrcNotFound  = errors.New(errors.ErrBadRequest, "src_file_not_found")
+	ErrFileDstExist     = errors.New(errors.ErrBadRequest, "dst_file_exist")
 	ErrFileInvalidToken = errors.New(errors.ErrBadRequest, "invalid_token")
+	ErrFileInvalidRange = errors.New(errors.ErrBadRequest, "invalid_range")
+	ErrChecksumMismatch = errors.New(errors.ErrBadRequest, "checksum_mismatch")
+	// Uploads
+	ErrUploadTooLarge            = errors.New(errors.ErrBadRequest, "file_too_large")
+	ErrUploadTypeNotAllowed      = errors.New(errors.ErrBadRequest, "file_type_not_allowed")
+	ErrUploadExtensionNotAllowed = errors.New(errors.ErrBadRequest, "file_extension_not_allowed")
+	// Archives
+	ErrInvalidArchiveFormat    = errors.New(errors.ErrBadRequest, "invalid_archive_format")
+	ErrArchiveInvalid          = errors.New(errors.ErrBadRequest, "invalid_archive")
+	ErrArchiveInvalidEntryPath = errors.New(errors.ErrBadRequest, "invalid_entry_path")
+	ErrArchiveUnsupportedEntry = errors.New(errors.ErrBadRequest, "unsupported_entry")
+	ErrArchiveTooLarge         = errors.New(errors.ErrBadRequest, "archive_too_large")
+	ErrArchiveTooManyEntries   = errors.New(errors.ErrBadRequest, "archive_too_many_entries")
+	// Share links
+	ErrShareLinkInvalidExpires      = errors.New(errors.ErrBadRequest, "invalid_expires")
+	ErrShareLinkInvalidMaxDownloads = errors.New(errors.ErrBadRequest, "invalid_max_downloads")
+	ErrShareLinkInvalidFilename     = errors.New(errors.ErrBadRequest, "invalid_filename")
+	ErrShareLinkNotFound            = errors.New(errors.ErrBadRequest, "share_link_not_found")
 )
@@ -0,0 +1,313 @@
+package adapter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const (
+	// Default max total uncompressed size of an uploaded archive.
+	ArchiveDefaultMaxSize = 1 << 30
+	// Default max number of entries of an uploaded archive.
+	ArchiveDefaultMaxEntries = 10_000
+)
+
+// ExtractArchive unpacks a zip or tar(.gz) archive into the dir data.Path
+// with CreateDir and CreateFile. Entries escaping the dir, links and other
+// special entries are not written and reported as failed or skipped, dirs
+// are merged and existing files follow data.Overwrite. One entry is held in
+// memory at a time, a zip archive is read into memory as a whole. Exceeded
+// limits and broken archives abort the upload, the result then holds the
+// entries processed so far.
+func ExtractArchive(ctx context.Context, files Interface, authToken string, data UploadArchiveData) (*UploadArchiveResult, error) {
+	if !data.Format.Valid() {
+		return nil, ErrInvalidArchiveFormat
+	}
+	overwrite := data.Overwrite
+	switch overwrite {
+	case "":
+		overwrite = OverwriteFail
+	case OverwriteFail, OverwriteReplace, OverwriteSkip:
+	default:
+		return nil, ErrInvalidOverwrite
+	}
+
+	e := &archiveExtractor{
+		ctx:        ctx,
+		files:      files,
+		authToken:  authToken,
+		dest:       path.Clean("/" + data.Path),
+		overwrite:  overwrite,
+		remaining:  data.MaxSize,
+		maxEntries: data.MaxEntries,
+		dirs:       make(map[string]bool),
+		result:     &UploadArchiveResult{},
+	}
+	if e.remaining <= 0 {
+		e.remaining = ArchiveDefaultMaxSize
+	}
+	if e.maxEntries <= 0 {
+		e.maxEntries = ArchiveDefaultMaxEntries
+	}
+
+	// Destination dir
+	if _, err := e.mkdirAll(e.dest); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch data.Format {
+	case ArchiveFormatZip:
+		err = e.zip(data.File)
+	case ArchiveFormatTar:
+		err = e.tar(data.File)
+	case ArchiveFormatTarGz:
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(data.File)
+		if err != nil {
+			return e.result, fmt.Errorf("%w: %w", ErrArchiveInvalid, err)
+		}
+		defer gz.Close()
+		err = e.tar(gz)
+	}
+
+	return e.result, err
+}
+
+type archiveExtractor struct {
+	ctx        context.Context
+	files      Interface
+	authToken  string
+	dest       string
+	overwrite  OverwritePolicy
+	remaining  int64
+	maxEntries int
+	dirs       map[string]bool
+	result     *UploadArchiveResult
+}
+
+func (e *archiveExtractor) zip(r io.Reader) error {
+	// Zip needs random access, read it within the size limit
+	content, err := io.ReadAll(io.LimitReader(r, e.remaining+1))
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+	if int64(len(content)) > e.remaining {
+		return ErrArchiveTooLarge
+	}
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrArchiveInvalid, err)
+	}
+	if len(zr.File) > e.maxEntries {
+		return ErrArchiveTooManyEntries
+	}
+
+	for _, zf := range zr.File {
+		if err := e.ctx.Err(); err != nil {
+			return err
+		}
+
+		mode := zf.Mode()
+		switch {
+		case mode.IsDir():
+			e.dir(zf.Name)
+		case mode.IsRegular():
+			rc, err := zf.Open()
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrArchiveInvalid, err)
+			}
+			err = e.file(zf.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			e.skip(zf.Name, mode)
+		}
+	}
+
+	return nil
+}
+
+func (e *archiveExtractor) tar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for entries := 1; ; entries++ {
+		if err := e.ctx.Err(); err != nil {
+			return err
+		}
+
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrArchiveInvalid, err)
+		}
+		if entries > e.maxEntries {
+			return ErrArchiveTooManyEntries
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			e.dir(header.Name)
+		case tar.TypeReg:
+			if err := e.file(header.Name, tr); err != nil {
+				return err
+			}
+		default:
+			e.skip(header.Name, header.FileInfo().Mode())
+		}
+	}
+}
+
+// Helper for create a dir entry
+func (e *archiveExtractor) dir(name string) {
+	entry := UploadArchiveEntryResult{IsDir: true}
+	entry.Path, entry.Err = e.entryPath(name)
+	if entry.Err == nil {
+		var created bool
+		created, entry.Err = e.mkdirAll(entry.Path)
+		if entry.Err == nil && !created {
+			entry.Err = ErrDirExist
+		}
+	}
+
+	switch {
+	case entry.Err == nil:
+		entry.Status = ArchiveEntryCreated
+	case errors.Is(entry.Err, ErrDirExist):
+		entry.Status = ArchiveEntrySkipped
+	default:
+		entry.Status = ArchiveEntryFailed
+	}
+	e.result.Entries = append(e.result.Entries, entry)
+}
+
+// Helper for upload a file entry, only limit and context errors are returned
+func (e *archiveExtractor) file(name string, r io.Reader) error {
+	entry := UploadArchiveEntryResult{}
+	entry.Path, entry.Err = e.entryPath(name)
+
+	// Read content within the size limit, the declared size may be forged
+	content, err := io.ReadAll(io.LimitReader(r, e.remaining+1))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrArchiveInvalid, err)
+	}
+	if int64(len(content)) > e.remaining {
+		return ErrArchiveTooLarge
+	}
+	e.remaining -= int64(len(content))
+	entry.Size = int64(len(content))
+
+	if entry.Err == nil {
+		entry.Status, entry.Err = e.upload(entry.Path, content)
+		if err := e.ctx.Err(); err != nil {
+			return err
+		}
+	} else {
+		entry.Status = ArchiveEntryFailed
+	}
+	e.result.Entries = append(e.result.Entries, entry)
+
+	return nil
+}
+
+// Helper for report links and special files as skipped
+func (e *archiveExtractor) skip(name string, mode fs.FileMode) {
+	entryPath, err := e.entryPath(name)
+	if err != nil {
+		e.result.Entries = append(e.result.Entries, UploadArchiveEntryResult{
+			Path:   entryPath,
+			Status: ArchiveEntryFailed,
+			Err:    err,
+		})
+		return
+	}
+	e.result.Entries = append(e.result.Entries, UploadArchiveEntryResult{
+		Path:   entryPath,
+		IsDir:  mode.IsDir(),
+		Status: ArchiveEntrySkipped,
+		Err:    ErrArchiveUnsupportedEntry,
+	})
+}
+
+// Helper for upload file content according to the overwrite policy
+func (e *archiveExtractor) upload(filePath string, content []byte) (ArchiveEntryStatus, error) {
+	dir, name := path.Dir(filePath), path.Base(filePath)
+	if _, err := e.mkdirAll(dir); err != nil {
+		return ArchiveEntryFailed, err
+	}
+
+	data := CreateFileData{
+		Path: dir,
+		File: bytes.NewReader(content),
+		Name: name,
+	}
+	err := e.files.CreateFile(e.ctx, e.authToken, data)
+	if err == nil {
+		return ArchiveEntryCreated, nil
+	}
+	if !errors.Is(err, ErrFileExist) {
+		return ArchiveEntryFailed, err
+	}
+
+	// Existing file
+	switch e.overwrite {
+	case OverwriteSkip:
+		return ArchiveEntrySkipped, ErrFileExist
+	case OverwriteReplace:
+		data.File = bytes.NewReader(content)
+		if err := ReplaceFile(e.ctx, e.files, e.authToken, data); err != nil {
+			return ArchiveEntryFailed, err
+		}
+		return ArchiveEntryReplaced, nil
+	default:
+		return ArchiveEntryFailed, ErrFileDstExist
+	}
+}
+
+// Helper for create a dir with its missing parents, reports whether the
+// dir itself was created
+func (e *archiveExtractor) mkdirAll(dirPath string) (bool, error) {
+	if dirPath == "/" || e.dirs[dirPath] {
+		return false, nil
+	}
+	if _, err := e.mkdirAll(path.Dir(dirPath)); err != nil {
+		return false, err
+	}
+
+	err := e.files.CreateDir(e.ctx, e.authToken, dirPath)
+	if err != nil && !errors.Is(err, ErrDirExist) {
+		return false, err
+	}
+	e.dirs[dirPath] = true
+
+	return err == nil, nil
+}
+
+// Helper for resolve the path of an entry within the destination dir,
+// rejects absolute names, names of the dir itself and names escaping it
+// (zip slip)
+func (e *archiveExtractor) entryPath(name string) (string, error) {
+	invalid := name == "" || path.Clean(name) == "." || strings.HasPrefix(name, "/") ||
+		strings.Contains(name, "\\") || (len(name) > 1 && name[1] == ':')
+	for _, elem := range strings.Split(name, "/") {
+		if elem == ".." {
+			invalid = true
+		}
+	}
+	if invalid {
+		return name, ErrArchiveInvalidEntryPath
+	}
+	return path.Join(e.dest, name), nil
+}
@@ -0,0 +1,49 @@
+package adapter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEntryPath(t *testing.T) {
+	e := &archiveExtractor{dest: "/uploads"}
+
+	tests := []struct {
+		name string
+		want string
+		err  error
+	}{
+		{"a.txt", "/uploads/a.txt", nil},
+		{"dir/a.txt", "/uploads/dir/a.txt", nil},
+		{"dir/", "/uploads/dir", nil},
+		{"./a.txt", "/uploads/a.txt", nil},
+		{"a..b.txt", "/uploads/a..b.txt", nil},
+		{"..a/b.txt", "/uploads/..a/b.txt", nil},
+		{"", "", ErrArchiveInvalidEntryPath},
+		{".", "", ErrArchiveInvalidEntryPath},
+		{"./", "", ErrArchiveInvalidEntryPath},
+		{"./.", "", ErrArchiveInvalidEntryPath},
+		{"..", "", ErrArchiveInvalidEntryPath},
+		{"../a.txt", "", ErrArchiveInvalidEntryPath},
+		{"dir/../../a.txt", "", ErrArchiveInvalidEntryPath},
+		{"dir/../a.txt", "", ErrArchiveInvalidEntryPath},
+		{"dir/..", "", ErrArchiveInvalidEntryPath},
+		{"/a.txt", "", ErrArchiveInvalidEntryPath},
+		{"/etc/passwd", "", ErrArchiveInvalidEntryPath},
+		{"..\\a.txt", "", ErrArchiveInvalidEntryPath},
+		{"dir\\a.txt", "", ErrArchiveInvalidEntryPath},
+		{"C:/a.txt", "", ErrArchiveInvalidEntryPath},
+		{"c:a.txt", "", ErrArchiveInvalidEntryPath},
+		{"C:\\Windows\\a.txt", "", ErrArchiveInvalidEntryPath},
+	}
+	for _, tt := range tests {
+		got, err := e.entryPath(tt.name)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("entryPath(%q) error = %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if tt.err == nil && got != tt.want {
+			t.Errorf("entryPath(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package adapter
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Extensions rejected by every UploadPolicy unless AllowDangerousExtensions is set.
+var DangerousExtensions = []string{
+	".apk", ".app", ".bat", ".cmd", ".com", ".cpl", ".dll", ".exe", ".hta",
+	".jar", ".js", ".jse", ".lnk", ".msc", ".msi", ".msp", ".pif", ".ps1",
+	".reg", ".scr", ".sh", ".vb", ".vbe", ".vbs", ".wsf", ".wsh",
+}
+
+// Double extensions rejected with DangerousExtensions. The final extension
+// is harmless, but servers with handlers per extension run the inner one.
+var DangerousDoubleExtensions = []string{
+	".asp.gif", ".asp.jpg", ".asp.png", ".aspx.gif", ".aspx.jpg", ".aspx.png",
+	".jsp.gif", ".jsp.jpg", ".jsp.png", ".php.gif", ".php.jpg", ".php.png",
+}
+
+// UploadPolicy validates CreateFile uploads before they are sent.
+// Media types may use a subtype wildcard, for example "image/*".
+type UploadPolicy struct {
+	// Max file size in bytes, unlimited if zero
+	MaxSize int64
+	// Allowed media types, any if empty
+	AllowedTypes []string
+	// Denied media types, checked after AllowedTypes
+	DeniedTypes []string
+	// Denied extensions in addition to DangerousExtensions, entries with
+	// two dots are double extensions
+	DeniedExtensions         []string
+	AllowDangerousExtensions bool
+}
+
+// UploadPolicyError is returned by CreateFile for uploads rejected by the
+// UploadPolicy, it unwraps to ErrUploadTooLarge, ErrUploadTypeNotAllowed
+// or ErrUploadExtensionNotAllowed.
+type UploadPolicyError struct {
+	Err      error
+	Name     string
+	MimeType string
+	Size     int64
+}
+
+func (e *UploadPolicyError) Error() string {
+	return fmt.Sprintf("%s: name: %s, mime type: %s, size: %d", e.Err, e.Name, e.MimeType, e.Size)
+}
+
+func (e *UploadPolicyError) Unwrap() error {
+	return e.Err
+}
+
+// Bytes read by DetectContentType
+const sniffLen = 512
+
+// DetectContentType sniffs the media type from the first 512 bytes of
+// content, the file extension is used if the content is not recognized.
+func DetectContentType(name string, content []byte) string {
+	contentType := http.DetectContentType(content)
+	if contentType == "application/octet-stream" {
+		if byExtension := mime.TypeByExtension(path.Ext(name)); byExtension != "" {
+			return byExtension
+		}
+	}
+	return contentType
+}
+
+// Helper for validate upload name, detected media type and size
+func (p *UploadPolicy) validate(name string, contentType string, size int64) error {
+	if p == nil {
+		return nil
+	}
+
+	policyError := func(err error) error {
+		return &UploadPolicyError{err, name, contentType, size}
+	}
+
+	// Size
+	if p.MaxSize > 0 && size > p.MaxSize {
+		return policyError(ErrUploadTooLarge)
+	}
+
+	// Extension, the final one and listed double extensions
+	if !p.AllowDangerousExtensions && (matchExtension(DangerousExtensions, name) || matchExtension(DangerousDoubleExtensions, name)) {
+		return policyError(ErrUploadExtensionNotAllowed)
+	}
+	if matchExtension(p.DeniedExtensions, name) {
+		return policyError(ErrUploadExtensionNotAllowed)
+	}
+
+	// Media type
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	if len(p.AllowedTypes) > 0 && !matchMediaType(p.AllowedTypes, mediaType) {
+		return policyError(ErrUploadTypeNotAllowed)
+	}
+	if matchMediaType(p.DeniedTypes, mediaType) {
+		return policyError(ErrUploadTypeNotAllowed)
+	}
+
+	return nil
+}
+
+// Helper for match the final extension of a name against a list, double
+// extensions in the list match the end of the name. Trailing dots and
+// spaces are trimmed, Windows drops them so "evil.exe." is "evil.exe"
+func matchExtension(list []string, name string) bool {
+	name = strings.ToLower(strings.TrimRight(name, ". "))
+	for _, ext := range list {
+		ext = strings.ToLower(ext)
+		if strings.Count(ext, ".") > 1 {
+			if len(name) > len(ext) && strings.HasSuffix(name, ext) {
+				return true
+			}
+			continue
+		}
+		if path.Ext(name) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper for match media type against a list with subtype wildcards
+func matchMediaType(list []string, mediaType string) bool {
+	for _, item := range list {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == mediaType || item == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(item, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
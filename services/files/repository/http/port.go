@@ -2,6 +2,7 @@ package adapter
 
 import (
 	"context"
+	"io"
 )
 
 type Interface interface {
@@ -9,12 +10,28 @@ type Interface interface {
 	CreateDir(ctx context.Context, authToken string, path string) error
 	RenameDir(ctx context.Context, authToken string, data RenameDirData) error
 	DeleteDir(ctx context.Context, authToken string, path string) error
-	// Files
+	CopyDir(ctx context.Context, authToken string, data CopyDirData) error
+	MoveDir(ctx context.Context, authToken string, data MoveDirData) error
+	// Files, GetFile and StreamFile verify the content with the checksum
+	// reported by DownloadFile, a range of StreamFileRange is unverified
+	// and shorter than length at the end of file
 	GetFile(ctx context.Context, authToken string, path string) ([]byte, error)
 	StreamFile(ctx context.Context, token string) ([]byte, error)
+	StreamFileRange(ctx context.Context, token string, offset int64, length int64) ([]byte, error)
 	DownloadFile(ctx context.Context, authToken string, path string) (*DownloadFileResult, error)
 	ListFiles(ctx context.Context, authToken string, path string) ([]FileResult, error)
+	StatFile(ctx context.Context, authToken string, path string) (*StatFileResult, error)
 	CreateFile(ctx context.Context, authToken string, data CreateFileData) error
 	RenameFile(ctx context.Context, authToken string, data RenameFileData) error
 	DeleteFile(ctx context.Context, authToken string, path string) error
+	CopyFile(ctx context.Context, authToken string, data CopyFileData) error
+	MoveFile(ctx context.Context, authToken string, data MoveFileData) error
+	// Archives
+	DownloadArchive(ctx context.Context, authToken string, paths []string, format ArchiveFormat) (io.ReadCloser, error)
+	UploadArchive(ctx context.Context, authToken string, data UploadArchiveData) (*UploadArchiveResult, error)
+	// Share links
+	CreateShareLink(ctx context.Context, authToken string, path string, data CreateShareLinkData) (*ShareLinkResult, error)
+	ListShareLinks(ctx context.Context, authToken string, path string) ([]ShareLinkResult, error)
+	RevokeShareLink(ctx context.Context, authToken string, id string) error
+	ShareLinkUrl(token string) string
 }
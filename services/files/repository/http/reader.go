@@ -0,0 +1,253 @@
+package adapter
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+)
+
+const (
+	// Default size of a block fetched by a single range request.
+	FileReaderDefaultBlockSize = 1 << 20
+	// Default number of blocks kept in the reader cache.
+	FileReaderDefaultCacheBlocks = 8
+)
+
+type FileReaderConfig struct {
+	// Block size in bytes, FileReaderDefaultBlockSize if zero
+	BlockSize int64
+	// Number of cached blocks, FileReaderDefaultCacheBlocks if zero
+	CacheBlocks int
+	// File size in bytes, looked up with StatFile if nil
+	Size *int64
+}
+
+// FileReader reads a stored file on demand with range requests.
+// It implements io.Reader, io.ReaderAt and io.Seeker. ReadAt is safe
+// for concurrent use, Read and Seek are not. Content read with Read from
+// the start to the end is verified with the file checksum, the final Read
+// returns ErrChecksumMismatch on a mismatch. ReadAt is not verified.
+type FileReader struct {
+	ctx         context.Context
+	files       Interface
+	authToken   string
+	path        string
+	size        int64
+	blockSize   int64
+	cacheBlocks int
+	offset      int64
+	// Hash of the content read sequentially up to hashed, nil once verified
+	hash   hash.Hash
+	hashed int64
+
+	mu       sync.Mutex
+	blocks   map[int64]*list.Element
+	lru      *list.List
+	checksum *string
+}
+
+type fileReaderBlock struct {
+	index int64
+	data  []byte
+}
+
+func NewFileReader(ctx context.Context, files Interface, authToken string, filePath string, config *FileReaderConfig) (*FileReader, error) {
+	if config == nil {
+		config = &FileReaderConfig{}
+	}
+
+	r := &FileReader{
+		ctx:         ctx,
+		files:       files,
+		authToken:   authToken,
+		path:        filePath,
+		blockSize:   config.BlockSize,
+		cacheBlocks: config.CacheBlocks,
+		hash:        sha256.New(),
+		blocks:      make(map[int64]*list.Element),
+		lru:         list.New(),
+	}
+	if r.blockSize <= 0 {
+		r.blockSize = FileReaderDefaultBlockSize
+	}
+	if r.cacheBlocks <= 0 {
+		r.cacheBlocks = FileReaderDefaultCacheBlocks
+	}
+
+	// Resolve file size
+	if config.Size != nil {
+		r.size = *config.Size
+		return r, nil
+	}
+	size, err := r.lookupSize()
+	if err != nil {
+		return nil, err
+	}
+	r.size = size
+
+	return r, nil
+}
+
+func (r *FileReader) Size() int64 {
+	return r.size
+}
+
+func (r *FileReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.offset)
+
+	// Verify sequential reads at the end of the file
+	if r.hash != nil && r.hashed == r.offset {
+		r.hash.Write(p[:n])
+		r.hashed += int64(n)
+		if r.hashed == r.size {
+			if verifyErr := r.verify(); verifyErr != nil {
+				err = verifyErr
+			}
+		}
+	}
+
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *FileReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *FileReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("read at: negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	var n int
+	for n < len(p) && off < r.size {
+		index := off / r.blockSize
+		data, err := r.block(index)
+		if err != nil {
+			return n, err
+		}
+		c := copy(p[n:], data[off-index*r.blockSize:])
+		n += c
+		off += int64(c)
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Helper for get block from cache or fetch it from service
+func (r *FileReader) block(index int64) ([]byte, error) {
+	r.mu.Lock()
+	if el, ok := r.blocks[index]; ok {
+		r.lru.MoveToFront(el)
+		r.mu.Unlock()
+		return el.Value.(*fileReaderBlock).data, nil
+	}
+	r.mu.Unlock()
+
+	data, err := r.fetch(index)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if el, ok := r.blocks[index]; ok {
+		r.lru.MoveToFront(el)
+		return el.Value.(*fileReaderBlock).data, nil
+	}
+	r.blocks[index] = r.lru.PushFront(&fileReaderBlock{index, data})
+	for r.lru.Len() > r.cacheBlocks {
+		el := r.lru.Back()
+		r.lru.Remove(el)
+		delete(r.blocks, el.Value.(*fileReaderBlock).index)
+	}
+
+	return data, nil
+}
+
+// Helper for fetch block with a range request
+func (r *FileReader) fetch(index int64) ([]byte, error) {
+	offset := index * r.blockSize
+	length := min(r.blockSize, r.size-offset)
+
+	// Stream tokens are single use, request a new one for every block
+	download, err := r.files.DownloadFile(r.ctx, r.authToken, r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	r.setChecksum(download.Checksum)
+
+	data, err := r.files.StreamFileRange(r.ctx, download.Token, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != length {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return data, nil
+}
+
+// Helper for keep the first checksum reported by the service
+func (r *FileReader) setChecksum(checksum *string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.checksum == nil {
+		r.checksum = checksum
+	}
+}
+
+// Helper for verify the sequentially read content, once
+func (r *FileReader) verify() error {
+	r.mu.Lock()
+	checksum := r.checksum
+	r.mu.Unlock()
+
+	sum := r.hash.Sum(nil)
+	r.hash = nil
+	if checksum == nil {
+		return nil
+	}
+	return compareChecksum(sum, *checksum)
+}
+
+// Helper for lookup file size
+func (r *FileReader) lookupSize() (int64, error) {
+	stat, err := r.files.StatFile(r.ctx, r.authToken, r.path)
+	if err != nil {
+		return 0, err
+	}
+	if stat.IsDir {
+		return 0, ErrFileNotFound
+	}
+	if stat.Size == nil {
+		return 0, fmt.Errorf("file %s: unknown size", r.path)
+	}
+	r.setChecksum(stat.Checksum)
+	return *stat.Size, nil
+}
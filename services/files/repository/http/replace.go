@@ -0,0 +1,40 @@
+package adapter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"path"
+)
+
+// ReplaceFile uploads the file under a temporary name next to the target
+// and moves it over the target, so a failed upload keeps the existing
+// file. The temporary file is deleted if the upload or the move fails.
+func ReplaceFile(ctx context.Context, files Interface, authToken string, data CreateFileData) error {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	filePath := path.Join(data.Path, data.Name)
+	tmpName := data.Name + ".tmp-" + hex.EncodeToString(suffix)
+	tmpPath := path.Join(data.Path, tmpName)
+
+	err := files.CreateFile(ctx, authToken, CreateFileData{
+		Path: data.Path,
+		File: data.File,
+		Name: tmpName,
+	})
+	if err == nil {
+		err = files.MoveFile(ctx, authToken, MoveFileData{
+			SrcPath:   tmpPath,
+			DstPath:   filePath,
+			Overwrite: OverwriteReplace,
+		})
+	}
+	if err != nil {
+		// Best effort cleanup, also after cancellation
+		_ = files.DeleteFile(context.WithoutCancel(ctx), authToken, tmpPath)
+		return err
+	}
+	return nil
+}
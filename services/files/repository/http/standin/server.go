@@ -0,0 +1,716 @@
+package standin
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+// Max size of a multipart upload kept in memory
+const maxUploadMemory = 32 << 20
+
+// NewServer returns an in-memory stand-in of the files service with the
+// routes of the files adapter, for local development and tests. Auth
+// tokens are not checked. Errors are returned as "bad_request:<code>"
+// bodies with status 400, like the service.
+func NewServer() *Server {
+	s := &Server{
+		nodes:  map[string]*node{"/": {isDir: true, created: time.Now(), updated: time.Now()}},
+		tokens: make(map[string]string),
+		links:  make(map[string]*adapter.ShareLinkResult),
+		mux:    http.NewServeMux(),
+	}
+
+	// Dirs
+	s.mux.HandleFunc("POST /files/dir/{path}", s.createDir)
+	s.mux.HandleFunc("PATCH /files/dir/{$}", s.renameDir)
+	s.mux.HandleFunc("POST /files/dir/copy", s.copyDir)
+	s.mux.HandleFunc("POST /files/dir/move", s.moveDir)
+	// Files
+	s.mux.HandleFunc("GET /files/download/{path}", s.downloadFile)
+	s.mux.HandleFunc("GET /files/download/stream/{token}", s.streamFile)
+	s.mux.HandleFunc("GET /files/list/{path}", s.listFiles)
+	s.mux.HandleFunc("GET /files/stat/{path}", s.statFile)
+	s.mux.HandleFunc("POST /files/{path}", s.createFile)
+	s.mux.HandleFunc("PATCH /files/{$}", s.renameFile)
+	s.mux.HandleFunc("DELETE /files/{path}", s.deleteFile)
+	s.mux.HandleFunc("POST /files/copy", s.copyFile)
+	s.mux.HandleFunc("POST /files/move", s.moveFile)
+	// Share links
+	s.mux.HandleFunc("POST /files/share/{path}", s.createShareLink)
+	s.mux.HandleFunc("GET /files/share/list/{path}", s.listShareLinks)
+	s.mux.HandleFunc("DELETE /files/share/{id}", s.revokeShareLink)
+	s.mux.HandleFunc("GET /files/share/stream/{token}", s.streamShareLink)
+
+	return s
+}
+
+type Server struct {
+	mu     sync.Mutex
+	nodes  map[string]*node
+	tokens map[string]string
+	links  map[string]*adapter.ShareLinkResult
+	mux    *http.ServeMux
+}
+
+type node struct {
+	isDir    bool
+	content  []byte
+	mimeType string
+	created  time.Time
+	updated  time.Time
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// DeleteDir sends the plain path, the mux would redirect it
+	if r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/files/dir/") {
+		s.deleteDir(w, strings.TrimPrefix(r.URL.Path, "/files/dir/"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Dirs
+
+func (s *Server) createDir(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dirPath, ok := pathValue(r, "path")
+	if !ok || !s.isDir(path.Dir(dirPath)) {
+		writeError(w, "invalid_path")
+		return
+	}
+	if s.nodes[dirPath] != nil {
+		writeError(w, "dir_exist")
+		return
+	}
+	now := time.Now()
+	s.nodes[dirPath] = &node{isDir: true, created: now, updated: now}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) renameDir(w http.ResponseWriter, r *http.Request) {
+	var data adapter.RenameDirData
+	if !readJson(w, r, &data) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldPath, ok := cleanPath(data.OldPath)
+	if !ok || oldPath == "/" {
+		writeError(w, "invalid_old_path")
+		return
+	}
+	newPath, ok := cleanPath(data.NewPath)
+	if !ok || !s.isDir(path.Dir(newPath)) || isWithin(newPath, oldPath) {
+		writeError(w, "invalid_new_path")
+		return
+	}
+	if !s.isDir(oldPath) {
+		writeError(w, "old_dir_not_found")
+		return
+	}
+	if s.nodes[newPath] != nil {
+		writeError(w, "new_dir_exist")
+		return
+	}
+	s.moveTree(oldPath, newPath)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteDir(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dirPath, ok := cleanPath(name)
+	if !ok || dirPath == "/" {
+		writeError(w, "invalid_path")
+		return
+	}
+	if !s.isDir(dirPath) {
+		writeError(w, "dir_not_found")
+		return
+	}
+	for name := range s.nodes {
+		if isWithin(name, dirPath) {
+			delete(s.nodes, name)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) copyDir(w http.ResponseWriter, r *http.Request) {
+	s.transferDir(w, r, false)
+}
+
+func (s *Server) moveDir(w http.ResponseWriter, r *http.Request) {
+	s.transferDir(w, r, true)
+}
+
+// Helper for copy or move a dir tree, dirs are merged and files follow
+// the overwrite policy
+func (s *Server) transferDir(w http.ResponseWriter, r *http.Request, move bool) {
+	var data adapter.CopyDirData
+	if !readJson(w, r, &data) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	srcPath, ok := cleanPath(data.SrcPath)
+	if !ok || srcPath == "/" {
+		writeError(w, "invalid_src_path")
+		return
+	}
+	dstPath, ok := cleanPath(data.DstPath)
+	if !ok || !s.isDir(path.Dir(dstPath)) || isWithin(dstPath, srcPath) {
+		writeError(w, "invalid_dst_path")
+		return
+	}
+	overwrite, ok := overwritePolicy(data.Overwrite)
+	if !ok {
+		writeError(w, "invalid_overwrite")
+		return
+	}
+	if !s.isDir(srcPath) {
+		writeError(w, "src_dir_not_found")
+		return
+	}
+
+	// Check conflicts before changing anything
+	names := s.tree(srcPath)
+	if dst := s.nodes[dstPath]; dst != nil {
+		if !dst.isDir || overwrite == adapter.OverwriteFail {
+			writeError(w, "dst_dir_exist")
+			return
+		}
+	}
+	for _, name := range names {
+		src, dst := s.nodes[name], s.nodes[dstPath+strings.TrimPrefix(name, srcPath)]
+		if dst != nil && src.isDir != dst.isDir {
+			writeError(w, "dst_file_exist")
+			return
+		}
+	}
+
+	for _, name := range names {
+		target := dstPath + strings.TrimPrefix(name, srcPath)
+		src, dst := s.nodes[name], s.nodes[target]
+		if dst != nil && (dst.isDir || overwrite == adapter.OverwriteSkip) {
+			continue
+		}
+		s.nodes[target] = src.clone()
+	}
+	if move {
+		for _, name := range names {
+			delete(s.nodes, name)
+		}
+	}
+
+	if move {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Files
+
+func (s *Server) downloadFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath, ok := pathValue(r, "path")
+	if !ok {
+		writeError(w, "invalid_path")
+		return
+	}
+	file := s.nodes[filePath]
+	if file == nil || file.isDir {
+		writeError(w, "file_not_found")
+		return
+	}
+
+	token := randomId()
+	s.tokens[token] = filePath
+	checksum := file.checksum()
+	writeJson(w, http.StatusOK, adapter.DownloadFileResult{Token: token, Checksum: &checksum})
+}
+
+// Tokens are single use, ranges are served with status 206
+func (s *Server) streamFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	token := r.PathValue("token")
+	filePath, ok := s.tokens[token]
+	delete(s.tokens, token)
+	file := s.nodes[filePath]
+	s.mu.Unlock()
+
+	if !ok || file == nil || file.isDir {
+		writeError(w, "invalid_token")
+		return
+	}
+	if file.mimeType != "" {
+		w.Header().Set("Content-Type", file.mimeType)
+	}
+	http.ServeContent(w, r, "", file.updated, bytes.NewReader(file.content))
+}
+
+func (s *Server) listFiles(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dirPath, ok := pathValue(r, "path")
+	if !ok || !s.isDir(dirPath) {
+		writeError(w, "invalid_path")
+		return
+	}
+
+	list := []adapter.FileResult{}
+	for name, item := range s.nodes {
+		if name == dirPath || path.Dir(name) != dirPath {
+			continue
+		}
+		result := adapter.FileResult{Name: path.Base(name), IsDir: item.isDir}
+		if !item.isDir {
+			size := int64(len(item.content))
+			result.Size, result.MimeType = &size, &item.mimeType
+		}
+		list = append(list, result)
+	}
+	slices.SortFunc(list, func(a, b adapter.FileResult) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	writeJson(w, http.StatusOK, list)
+}
+
+func (s *Server) statFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath, ok := pathValue(r, "path")
+	if !ok {
+		writeError(w, "invalid_path")
+		return
+	}
+	item := s.nodes[filePath]
+	if item == nil {
+		writeError(w, "file_not_found")
+		return
+	}
+
+	result := adapter.StatFileResult{
+		Name:    path.Base(filePath),
+		IsDir:   item.isDir,
+		Updated: item.updated,
+		Created: item.created,
+	}
+	if !item.isDir {
+		size, checksum := int64(len(item.content)), item.checksum()
+		result.Size, result.MimeType, result.Checksum = &size, &item.mimeType, &checksum
+	}
+	writeJson(w, http.StatusOK, result)
+}
+
+func (s *Server) createFile(w http.ResponseWriter, r *http.Request) {
+	dirPath, ok := pathValue(r, "path")
+	if !ok {
+		writeError(w, "invalid_path")
+		return
+	}
+
+	// Read multipart file and checksum fields
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	part, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer part.Close()
+	content, err := io.ReadAll(part)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if checksum := r.FormValue("checksum_sha256"); checksum != "" {
+		if adapter.VerifyChecksum(content, checksum) != nil {
+			writeError(w, "checksum_mismatch")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := path.Join(dirPath, header.Filename)
+	if !s.isDir(dirPath) {
+		writeError(w, "dir_not_found")
+		return
+	}
+	if s.nodes[filePath] != nil {
+		writeError(w, "file_exist")
+		return
+	}
+	now := time.Now()
+	s.nodes[filePath] = &node{
+		content:  content,
+		mimeType: header.Header.Get("Content-Type"),
+		created:  now,
+		updated:  now,
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) renameFile(w http.ResponseWriter, r *http.Request) {
+	var data adapter.RenameFileData
+	if !readJson(w, r, &data) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldPath, ok := cleanPath(data.OldPath)
+	if !ok {
+		writeError(w, "invalid_old_path")
+		return
+	}
+	newPath, ok := cleanPath(data.NewPath)
+	if !ok || !s.isDir(path.Dir(newPath)) {
+		writeError(w, "invalid_new_path")
+		return
+	}
+	file := s.nodes[oldPath]
+	if file == nil || file.isDir {
+		writeError(w, "old_file_not_found")
+		return
+	}
+	if s.nodes[newPath] != nil {
+		writeError(w, "new_file_exist")
+		return
+	}
+	delete(s.nodes, oldPath)
+	s.nodes[newPath] = file
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath, ok := pathValue(r, "path")
+	if !ok {
+		writeError(w, "invalid_path")
+		return
+	}
+	file := s.nodes[filePath]
+	if file == nil || file.isDir {
+		writeError(w, "file_not_found")
+		return
+	}
+	delete(s.nodes, filePath)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) copyFile(w http.ResponseWriter, r *http.Request) {
+	s.transferFile(w, r, false)
+}
+
+func (s *Server) moveFile(w http.ResponseWriter, r *http.Request) {
+	s.transferFile(w, r, true)
+}
+
+// Helper for copy or move a file with the overwrite policy
+func (s *Server) transferFile(w http.ResponseWriter, r *http.Request, move bool) {
+	var data adapter.CopyFileData
+	if !readJson(w, r, &data) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	srcPath, ok := cleanPath(data.SrcPath)
+	if !ok {
+		writeError(w, "invalid_src_path")
+		return
+	}
+	dstPath, ok := cleanPath(data.DstPath)
+	if !ok || dstPath == "/" {
+		writeError(w, "invalid_dst_path")
+		return
+	}
+	overwrite, ok := overwritePolicy(data.Overwrite)
+	if !ok {
+		writeError(w, "invalid_overwrite")
+		return
+	}
+	src := s.nodes[srcPath]
+	if src == nil || src.isDir {
+		writeError(w, "src_file_not_found")
+		return
+	}
+	if !s.isDir(path.Dir(dstPath)) {
+		writeError(w, "dir_not_found")
+		return
+	}
+
+	dst := s.nodes[dstPath]
+	switch {
+	case dst == nil || srcPath == dstPath:
+	case dst.isDir || overwrite == adapter.OverwriteFail:
+		writeError(w, "dst_file_exist")
+		return
+	case overwrite == adapter.OverwriteSkip:
+		src = nil
+	}
+	if src != nil && srcPath != dstPath {
+		file := src.clone()
+		file.updated = time.Now()
+		s.nodes[dstPath] = file
+		if move {
+			delete(s.nodes, srcPath)
+		}
+	}
+
+	if move {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Share links
+
+func (s *Server) createShareLink(w http.ResponseWriter, r *http.Request) {
+	var data adapter.CreateShareLinkData
+	if !readJson(w, r, &data) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath, ok := pathValue(r, "path")
+	if !ok {
+		writeError(w, "invalid_path")
+		return
+	}
+	if file := s.nodes[filePath]; file == nil || file.isDir {
+		writeError(w, "file_not_found")
+		return
+	}
+	if data.Expires != nil && !data.Expires.After(time.Now()) {
+		writeError(w, "invalid_expires")
+		return
+	}
+	if data.MaxDownloads != nil && *data.MaxDownloads == 0 {
+		writeError(w, "invalid_max_downloads")
+		return
+	}
+	if data.Filename != nil && (*data.Filename == "" || strings.ContainsAny(*data.Filename, "/\\")) {
+		writeError(w, "invalid_filename")
+		return
+	}
+
+	item := &adapter.ShareLinkResult{
+		Id:           randomId(),
+		Token:        randomId(),
+		Path:         filePath,
+		Expires:      data.Expires,
+		MaxDownloads: data.MaxDownloads,
+		Filename:     data.Filename,
+		Created:      time.Now(),
+	}
+	s.links[item.Id] = item
+	writeJson(w, http.StatusCreated, item)
+}
+
+func (s *Server) listShareLinks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath, ok := pathValue(r, "path")
+	if !ok {
+		writeError(w, "invalid_path")
+		return
+	}
+
+	list := []adapter.ShareLinkResult{}
+	for _, item := range s.links {
+		if item.Path == filePath {
+			list = append(list, *item)
+		}
+	}
+	slices.SortFunc(list, func(a, b adapter.ShareLinkResult) int {
+		return a.Created.Compare(b.Created)
+	})
+	writeJson(w, http.StatusOK, list)
+}
+
+func (s *Server) revokeShareLink(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if s.links[id] == nil {
+		writeError(w, "share_link_not_found")
+		return
+	}
+	delete(s.links, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) streamShareLink(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var found *adapter.ShareLinkResult
+	for _, item := range s.links {
+		if item.Token == r.PathValue("token") {
+			found = item
+		}
+	}
+	var file *node
+	if found != nil {
+		file = s.nodes[found.Path]
+	}
+	usable := found != nil && file != nil && !file.isDir &&
+		(found.Expires == nil || found.Expires.After(time.Now())) &&
+		(found.MaxDownloads == nil || found.Downloads < *found.MaxDownloads)
+	if usable {
+		found.Downloads++
+	}
+	s.mu.Unlock()
+
+	if !usable {
+		http.NotFound(w, r)
+		return
+	}
+	filename := path.Base(found.Path)
+	if found.Filename != nil {
+		filename = *found.Filename
+	}
+	if file.mimeType != "" {
+		w.Header().Set("Content-Type", file.mimeType)
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	http.ServeContent(w, r, "", file.updated, bytes.NewReader(file.content))
+}
+
+// Helpers
+
+// Helper for check a dir exists, the caller holds the lock
+func (s *Server) isDir(name string) bool {
+	item := s.nodes[name]
+	return item != nil && item.isDir
+}
+
+// Helper for list a dir and everything below it, parents first
+func (s *Server) tree(root string) []string {
+	var names []string
+	for name := range s.nodes {
+		if isWithin(name, root) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Helper for move a dir and everything below it
+func (s *Server) moveTree(oldPath string, newPath string) {
+	for _, name := range s.tree(oldPath) {
+		s.nodes[newPath+strings.TrimPrefix(name, oldPath)] = s.nodes[name]
+		delete(s.nodes, name)
+	}
+}
+
+func (n *node) clone() *node {
+	clone := *n
+	clone.content = slices.Clone(n.content)
+	return &clone
+}
+
+func (n *node) checksum() string {
+	sum := sha256.Sum256(n.content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Helper for decode a base64 encoded path of the url
+func pathValue(r *http.Request, name string) (string, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(r.PathValue(name))
+	if err != nil {
+		return "", false
+	}
+	return cleanPath(string(decoded))
+}
+
+// Helper for check an absolute path, returns it cleaned
+func cleanPath(name string) (string, bool) {
+	if !strings.HasPrefix(name, "/") || strings.ContainsRune(name, 0) {
+		return "", false
+	}
+	return path.Clean(name), true
+}
+
+// Helper for check a path is root or below it
+func isWithin(name string, root string) bool {
+	return name == root || strings.HasPrefix(name, strings.TrimSuffix(root, "/")+"/")
+}
+
+// Helper for read an overwrite policy, fail if empty
+func overwritePolicy(policy adapter.OverwritePolicy) (adapter.OverwritePolicy, bool) {
+	switch policy {
+	case "":
+		return adapter.OverwriteFail, true
+	case adapter.OverwriteFail, adapter.OverwriteReplace, adapter.OverwriteSkip:
+		return policy, true
+	}
+	return "", false
+}
+
+func randomId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func readJson(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Helper for write a service error, the body is matched exactly
+func writeError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	io.WriteString(w, "bad_request:"+code)
+}
@@ -0,0 +1,86 @@
+package standin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+func encodePath(name string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(name))
+}
+
+func TestStatFile(t *testing.T) {
+	server := httptest.NewServer(NewServer())
+	defer server.Close()
+
+	// Upload /docs/a.txt
+	res, err := http.Post(server.URL+"/files/dir/"+encodePath("/docs"), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("hello"))
+	writer.Close()
+	res, err = http.Post(server.URL+"/files/"+encodePath("/docs"), writer.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("upload: status %d", res.StatusCode)
+	}
+
+	stat := func(name string) (int, []byte) {
+		t.Helper()
+		res, err := http.Get(server.URL + "/files/stat/" + encodePath(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		data, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode, data
+	}
+
+	status, data := stat("/docs/a.txt")
+	var file adapter.StatFileResult
+	if err := json.Unmarshal(data, &file); status != http.StatusOK || err != nil {
+		t.Fatalf("file: status %d, %v", status, err)
+	}
+	if file.Name != "a.txt" || file.IsDir || file.Size == nil || *file.Size != 5 || file.Checksum == nil {
+		t.Errorf("file: got %+v", file)
+	}
+	if file.Checksum != nil && adapter.VerifyChecksum([]byte("hello"), *file.Checksum) != nil {
+		t.Errorf("file: checksum %q does not match", *file.Checksum)
+	}
+
+	status, data = stat("/docs")
+	var dir adapter.StatFileResult
+	if err := json.Unmarshal(data, &dir); status != http.StatusOK || err != nil {
+		t.Fatalf("dir: status %d, %v", status, err)
+	}
+	if dir.Name != "docs" || !dir.IsDir || dir.Size != nil {
+		t.Errorf("dir: got %+v", dir)
+	}
+
+	status, data = stat("/docs/b.txt")
+	if status != http.StatusBadRequest || string(data) != "bad_request:file_not_found" {
+		t.Errorf("not found: status %d, body %q", status, data)
+	}
+}
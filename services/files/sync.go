@@ -0,0 +1,486 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+const (
+	// Default number of parallel sync transfers.
+	SyncDefaultConcurrency = 4
+)
+
+type SyncConfig struct {
+	Files adapter.Interface
+	// Parallel transfers, SyncDefaultConcurrency if zero
+	Concurrency int
+	// Upload bandwidth limit shared by all transfers, unlimited if zero
+	BytesPerSecond int64
+	// Started transfers per second, unlimited if zero
+	TransfersPerSecond int
+	// Delete remote files and dirs missing in the source
+	Delete bool
+	// Compare content hashes of files with equal size, the remote file is
+	// downloaded if the service reports no checksum
+	CompareContent bool
+	// Plan only, nothing is changed
+	DryRun      bool
+	ErrorPolicy ErrorPolicy
+	// Receives a line per planned or executed action, optional
+	Output io.Writer
+}
+
+// NewSync returns a sync engine that mirrors a source fs.FS (for example
+// os.DirFS or LocalFS) into a files service dir.
+func NewSync(config *SyncConfig) *Sync {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = SyncDefaultConcurrency
+	}
+	return &Sync{
+		files:          config.Files,
+		tree:           NewTree(&TreeConfig{Files: config.Files, Concurrency: concurrency, ErrorPolicy: config.ErrorPolicy}),
+		concurrency:    concurrency,
+		bytes:          newRateLimiter(float64(config.BytesPerSecond)),
+		transfers:      newRateLimiter(float64(config.TransfersPerSecond)),
+		delete:         config.Delete,
+		compareContent: config.CompareContent,
+		dryRun:         config.DryRun,
+		errorPolicy:    config.ErrorPolicy,
+		output:         config.Output,
+	}
+}
+
+type Sync struct {
+	files          adapter.Interface
+	tree           *Tree
+	concurrency    int
+	bytes          *rateLimiter
+	transfers      *rateLimiter
+	delete         bool
+	compareContent bool
+	dryRun         bool
+	errorPolicy    ErrorPolicy
+	output         io.Writer
+	outputMu       sync.Mutex
+}
+
+type SyncOp string
+
+const (
+	SyncOpMkdir  SyncOp = "mkdir"
+	SyncOpUpload SyncOp = "upload"
+	SyncOpUpdate SyncOp = "update"
+	SyncOpDelete SyncOp = "delete"
+)
+
+type SyncAction struct {
+	Op SyncOp
+	// Slash separated path relative to the synced dirs
+	Path string
+	Size int64
+	// Action error, nil on success or in dry run
+	Err error
+}
+
+type SyncResult struct {
+	Actions []SyncAction
+}
+
+// Run syncs src into the remote dir. It returns the planned actions with
+// their errors, and the error of the run according to the ErrorPolicy.
+func (s *Sync) Run(ctx context.Context, authToken string, src fs.FS, remoteDir string) (*SyncResult, error) {
+	remoteDir = path.Clean(remoteDir)
+
+	// Scan source
+	local := make(map[string]fs.FileInfo)
+	err := fs.WalkDir(src, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		local[name] = info
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan source: %w", err)
+	}
+
+	// Scan remote
+	remote, err := s.scanRemote(ctx, authToken, remoteDir)
+	if err != nil {
+		return nil, fmt.Errorf("scan remote: %w", err)
+	}
+
+	// Plan
+	actions, err := s.plan(ctx, authToken, src, remoteDir, local, remote)
+	if err != nil {
+		return nil, err
+	}
+	result := &SyncResult{actions}
+	if s.dryRun {
+		for _, action := range actions {
+			s.print(action)
+		}
+		return result, nil
+	}
+
+	// Execute
+	if err := s.tree.MkdirAll(ctx, authToken, remoteDir); err != nil {
+		return result, err
+	}
+	return result, s.execute(ctx, authToken, src, remoteDir, actions)
+}
+
+// Helper for collect remote entries by relative path
+func (s *Sync) scanRemote(ctx context.Context, authToken string, remoteDir string) (map[string]adapter.FileResult, error) {
+	prefix := remoteDir
+	if prefix != "/" {
+		prefix += "/"
+	}
+
+	remote := make(map[string]adapter.FileResult)
+	err := s.tree.Walk(ctx, authToken, remoteDir, func(name string, entry *adapter.FileResult, err error) error {
+		if err != nil {
+			// Missing remote dir is empty
+			if entry == nil && (errors.Is(err, adapter.ErrFileNotFound) || errors.Is(err, adapter.ErrDirInvalidPath)) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if name == remoteDir {
+			if !entry.IsDir {
+				return fmt.Errorf("%s: not a directory", remoteDir)
+			}
+			return nil
+		}
+		remote[strings.TrimPrefix(name, prefix)] = *entry
+		return nil
+	})
+
+	return remote, err
+}
+
+// Helper for compare source and remote entries
+func (s *Sync) plan(ctx context.Context, authToken string, src fs.FS, remoteDir string, local map[string]fs.FileInfo, remote map[string]adapter.FileResult) ([]SyncAction, error) {
+	var actions []SyncAction
+	var compare []SyncAction
+
+	for name, info := range local {
+		entry, exist := remote[name]
+		switch {
+		case info.IsDir() && !exist:
+			actions = append(actions, SyncAction{Op: SyncOpMkdir, Path: name})
+		case info.IsDir() && !entry.IsDir:
+			return nil, fmt.Errorf("%s: source dir is a remote file", name)
+		case info.IsDir():
+		case !exist:
+			actions = append(actions, SyncAction{Op: SyncOpUpload, Path: name, Size: info.Size()})
+		case entry.IsDir:
+			return nil, fmt.Errorf("%s: source file is a remote dir", name)
+		case entry.Size == nil || *entry.Size != info.Size():
+			actions = append(actions, SyncAction{Op: SyncOpUpdate, Path: name, Size: info.Size()})
+		case s.compareContent:
+			compare = append(compare, SyncAction{Op: SyncOpUpdate, Path: name, Size: info.Size()})
+		}
+	}
+
+	// Compare content of files with equal size
+	changed := make([]bool, len(compare))
+	err := s.parallel(ctx, len(compare), func(ctx context.Context, i int) error {
+		name := compare[i].Path
+		localHash, err := hashFile(src, name)
+		if err != nil {
+			return err
+		}
+		remotePath := path.Join(remoteDir, name)
+
+		// Prefer the checksum reported by the service
+		stat, err := s.files.StatFile(ctx, authToken, remotePath)
+		if err != nil {
+			return err
+		}
+		if stat.Checksum != nil {
+			changed[i] = !strings.EqualFold(*stat.Checksum, hex.EncodeToString(localHash[:]))
+			return nil
+		}
+
+		data, err := s.files.GetFile(ctx, authToken, remotePath)
+		if err != nil {
+			return err
+		}
+		changed[i] = sha256.Sum256(data) != localHash
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("compare content: %w", err)
+	}
+	for i, action := range compare {
+		if changed[i] {
+			actions = append(actions, action)
+		}
+	}
+
+	// Extraneous remote entries, only the topmost missing dir is deleted
+	if s.delete {
+		for name, entry := range remote {
+			if _, exist := local[name]; exist {
+				continue
+			}
+			if parent := path.Dir(name); parent != "." {
+				if _, kept := local[parent]; !kept {
+					continue
+				}
+			}
+			var size int64
+			if entry.Size != nil {
+				size = *entry.Size
+			}
+			actions = append(actions, SyncAction{Op: SyncOpDelete, Path: name, Size: size})
+		}
+	}
+
+	// Parents before children, deletes last
+	slices.SortFunc(actions, func(a, b SyncAction) int {
+		if a.Op == SyncOpDelete && b.Op != SyncOpDelete {
+			return 1
+		}
+		if a.Op != SyncOpDelete && b.Op == SyncOpDelete {
+			return -1
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return actions, nil
+}
+
+func (s *Sync) execute(ctx context.Context, authToken string, src fs.FS, remoteDir string, actions []SyncAction) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var errs []error
+	var mu sync.Mutex
+	fail := func(action *SyncAction, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		action.Err = err
+		errs = append(errs, &TreeEntryError{action.Path, err})
+		if s.errorPolicy == ErrorPolicyStop {
+			cancel()
+		}
+	}
+	done := func(action *SyncAction) {
+		if ctx.Err() == nil {
+			s.print(*action)
+		}
+	}
+
+	// Dirs, sorted parents first
+	for i := range actions {
+		action := &actions[i]
+		if action.Op != SyncOpMkdir || ctx.Err() != nil {
+			continue
+		}
+		err := s.files.CreateDir(ctx, authToken, path.Join(remoteDir, action.Path))
+		if err != nil && !errors.Is(err, adapter.ErrDirExist) {
+			fail(action, err)
+			continue
+		}
+		done(action)
+	}
+
+	// Transfers
+	var transfers []*SyncAction
+	for i := range actions {
+		if actions[i].Op == SyncOpUpload || actions[i].Op == SyncOpUpdate {
+			transfers = append(transfers, &actions[i])
+		}
+	}
+	s.parallel(ctx, len(transfers), func(ctx context.Context, i int) error {
+		action := transfers[i]
+		if err := s.upload(ctx, authToken, src, remoteDir, *action); err != nil {
+			fail(action, err)
+			return nil
+		}
+		done(action)
+		return nil
+	})
+
+	// Deletes
+	for i := range actions {
+		action := &actions[i]
+		if action.Op != SyncOpDelete || ctx.Err() != nil {
+			continue
+		}
+		if err := s.tree.RemoveAll(ctx, authToken, path.Join(remoteDir, action.Path)); err != nil {
+			fail(action, err)
+			continue
+		}
+		done(action)
+	}
+
+	if len(errs) == 0 {
+		return ctx.Err()
+	}
+	if s.errorPolicy == ErrorPolicyStop {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Sync) upload(ctx context.Context, authToken string, src fs.FS, remoteDir string, action SyncAction) error {
+	if err := s.transfers.wait(ctx, 1); err != nil {
+		return err
+	}
+	if err := s.bytes.wait(ctx, action.Size); err != nil {
+		return err
+	}
+
+	content, err := fs.ReadFile(src, action.Path)
+	if err != nil {
+		return err
+	}
+
+	remotePath := path.Join(remoteDir, action.Path)
+	data := adapter.CreateFileData{
+		Path: path.Dir(remotePath),
+		File: bytes.NewReader(content),
+		Name: path.Base(remotePath),
+	}
+	if action.Op != SyncOpUpdate {
+		return s.files.CreateFile(ctx, authToken, data)
+	}
+
+	// Upload next to the file and replace it, so a failed upload keeps
+	// the remote file
+	return adapter.ReplaceFile(ctx, s.files, authToken, data)
+}
+
+// Helper for run fn for n items with bounded concurrency, returns the
+// first error and cancels the rest
+func (s *Sync) parallel(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+	sem := make(chan struct{}, s.concurrency)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, i); err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	return first
+}
+
+func (s *Sync) print(action SyncAction) {
+	if s.output == nil {
+		return
+	}
+	s.outputMu.Lock()
+	defer s.outputMu.Unlock()
+
+	switch action.Op {
+	case SyncOpUpload, SyncOpUpdate:
+		fmt.Fprintf(s.output, "%s %s (%d bytes)\n", action.Op, action.Path, action.Size)
+	default:
+		fmt.Fprintf(s.output, "%s %s\n", action.Op, action.Path)
+	}
+}
+
+// Helper for hash source file content
+func hashFile(src fs.FS, name string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	file, err := src.Open(name)
+	if err != nil {
+		return sum, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return sum, err
+	}
+	copy(sum[:], hash.Sum(nil))
+
+	return sum, nil
+}
+
+// Rate limiter
+
+type rateLimiter struct {
+	mu   sync.Mutex
+	rate float64
+	next time.Time
+}
+
+// Helper for build limiter, nil limiter never waits
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &rateLimiter{rate: rate}
+}
+
+// Helper for wait until n units fit the rate
+func (l *rateLimiter) wait(ctx context.Context, n int64) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(time.Duration(float64(n) / l.rate * float64(time.Second)))
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,436 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+const (
+	// Default number of concurrent service requests of tree operations.
+	TreeDefaultConcurrency = 4
+)
+
+type ErrorPolicy int
+
+const (
+	// Abort the operation on the first failed entry
+	ErrorPolicyStop ErrorPolicy = iota
+	// Process all entries and return the joined entry errors
+	ErrorPolicyCollect
+)
+
+type TreeConfig struct {
+	Files adapter.Interface
+	// Concurrent service requests, TreeDefaultConcurrency if zero
+	Concurrency int
+	ErrorPolicy ErrorPolicy
+	// Copy files by downloading and uploading their content instead of
+	// server-side copy operations
+	ClientSideCopy bool
+}
+
+// NewTree returns recursive dir operations built on the files adapter.
+func NewTree(config *TreeConfig) *Tree {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = TreeDefaultConcurrency
+	}
+	return &Tree{
+		config.Files,
+		concurrency,
+		config.ErrorPolicy,
+		config.ClientSideCopy,
+	}
+}
+
+type Tree struct {
+	files          adapter.Interface
+	concurrency    int
+	errorPolicy    ErrorPolicy
+	clientSideCopy bool
+}
+
+// TreeEntryError is the error of a single entry of a tree operation.
+type TreeEntryError struct {
+	Path string
+	Err  error
+}
+
+func (e *TreeEntryError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *TreeEntryError) Unwrap() error {
+	return e.Err
+}
+
+// WalkFunc is called by Walk for every visited entry. A nil entry with
+// a non-nil err means the root could not be resolved, a dir entry with a
+// non-nil err means its listing failed. Returning fs.SkipDir skips the
+// dir (or the rest of the parent dir for files), fs.SkipAll stops the walk.
+type WalkFunc func(path string, entry *adapter.FileResult, err error) error
+
+// Walk visits the tree rooted at root in lexical order. The walk is
+// sequential, the ErrorPolicy does not apply since fn decides on errors.
+func (t *Tree) Walk(ctx context.Context, authToken string, root string, fn WalkFunc) error {
+	entry, err := t.stat(ctx, authToken, root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = t.walk(ctx, authToken, root, entry, fn)
+	}
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (t *Tree) walk(ctx context.Context, authToken string, name string, entry *adapter.FileResult, fn WalkFunc) error {
+	if err := fn(name, entry, nil); err != nil || !entry.IsDir {
+		if err == fs.SkipDir && entry.IsDir {
+			err = nil
+		}
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	list, err := t.files.ListFiles(ctx, authToken, name)
+	if err != nil {
+		if err = fn(name, entry, err); err != nil {
+			if err == fs.SkipDir {
+				err = nil
+			}
+			return err
+		}
+		return nil
+	}
+	slices.SortFunc(list, func(a, b adapter.FileResult) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for i := range list {
+		if err := t.walk(ctx, authToken, path.Join(name, list[i].Name), &list[i], fn); err != nil {
+			if err == fs.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MkdirAll creates the dir along with any missing parents.
+func (t *Tree) MkdirAll(ctx context.Context, authToken string, name string) error {
+	name = path.Clean(name)
+	if name == "/" || name == "." {
+		return nil
+	}
+	for i := 1; i <= len(name); i++ {
+		if i < len(name) && name[i] != '/' {
+			continue
+		}
+		err := t.files.CreateDir(ctx, authToken, name[:i])
+		if err != nil && !errors.Is(err, adapter.ErrDirExist) {
+			return &TreeEntryError{name[:i], err}
+		}
+	}
+	return nil
+}
+
+// RemoveAll removes the file or dir with all its content. A missing
+// name is not an error.
+func (t *Tree) RemoveAll(ctx context.Context, authToken string, name string) error {
+	entry, err := t.stat(ctx, authToken, name)
+	if errors.Is(err, adapter.ErrFileNotFound) {
+		return nil
+	}
+	if err != nil {
+		return &TreeEntryError{name, err}
+	}
+
+	run := t.newRun(ctx)
+	defer run.cancel()
+
+	if entry.IsDir {
+		run.removeDir(authToken, name)
+	} else {
+		run.call(name, func(ctx context.Context) error {
+			return t.files.DeleteFile(ctx, authToken, name)
+		})
+	}
+
+	return run.err()
+}
+
+// CopyTree copies the file or dir src to dst. Existing dirs in dst are
+// merged, existing files are reported as adapter.ErrFileDstExist. A dir
+// missing in dst is copied with a single server-side CopyDir, a merge
+// copies file by file.
+func (t *Tree) CopyTree(ctx context.Context, authToken string, src string, dst string) error {
+	src, dst = path.Clean(src), path.Clean(dst)
+	if dst == src || strings.HasPrefix(dst, src+"/") || src == "/" || src == "." {
+		return ErrTreeCopyIntoSelf
+	}
+
+	entry, err := t.stat(ctx, authToken, src)
+	if err != nil {
+		return &TreeEntryError{src, err}
+	}
+
+	run := t.newRun(ctx)
+	defer run.cancel()
+
+	// Files
+	if !entry.IsDir {
+		run.copyFile(authToken, src, dst)
+		return run.err()
+	}
+
+	// Dirs
+	if !t.clientSideCopy {
+		_, err := t.stat(ctx, authToken, dst)
+		if errors.Is(err, adapter.ErrFileNotFound) {
+			if err := t.MkdirAll(ctx, authToken, path.Dir(dst)); err != nil {
+				return err
+			}
+			run.call(src, func(ctx context.Context) error {
+				return t.files.CopyDir(ctx, authToken, adapter.CopyDirData{
+					SrcPath:   src,
+					DstPath:   dst,
+					Overwrite: adapter.OverwriteFail,
+				})
+			})
+			return run.err()
+		}
+		if err != nil {
+			return &TreeEntryError{dst, err}
+		}
+	}
+	if err := t.MkdirAll(ctx, authToken, dst); err != nil {
+		return err
+	}
+	run.copyDir(authToken, src, dst)
+
+	return run.err()
+}
+
+// Helper for lookup entry
+func (t *Tree) stat(ctx context.Context, authToken string, name string) (*adapter.FileResult, error) {
+	name = path.Clean(name)
+	if name == "/" || name == "." {
+		return &adapter.FileResult{Name: name, IsDir: true}, nil
+	}
+
+	stat, err := t.files.StatFile(ctx, authToken, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adapter.FileResult{
+		Name:     stat.Name,
+		IsDir:    stat.IsDir,
+		Size:     stat.Size,
+		MimeType: stat.MimeType,
+	}, nil
+}
+
+// Tree run
+
+type treeRun struct {
+	tree    *Tree
+	ctx     context.Context
+	cancel  context.CancelFunc
+	sem     chan struct{}
+	workers chan struct{}
+	mu      sync.Mutex
+	errs    []error
+}
+
+func (t *Tree) newRun(ctx context.Context) *treeRun {
+	ctx, cancel := context.WithCancel(ctx)
+	return &treeRun{
+		tree:    t,
+		ctx:     ctx,
+		cancel:  cancel,
+		sem:     make(chan struct{}, t.concurrency),
+		workers: make(chan struct{}, t.concurrency),
+	}
+}
+
+// Helper for run a service request within the concurrency limit,
+// reports whether it succeeded
+func (r *treeRun) call(name string, fn func(ctx context.Context) error) bool {
+	select {
+	case r.sem <- struct{}{}:
+	case <-r.ctx.Done():
+		r.fail(name, r.ctx.Err())
+		return false
+	}
+	err := fn(r.ctx)
+	<-r.sem
+
+	if err != nil {
+		r.fail(name, err)
+		return false
+	}
+	return true
+}
+
+// Helper for run fn in a new goroutine if a worker is free, otherwise in
+// the calling goroutine, keeps the goroutines within the concurrency limit
+func (r *treeRun) spawn(wg *sync.WaitGroup, fn func()) {
+	select {
+	case r.workers <- struct{}{}:
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-r.workers }()
+			fn()
+		}()
+	default:
+		fn()
+	}
+}
+
+func (r *treeRun) fail(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Errors caused by an earlier stop are not entry errors
+	if r.tree.errorPolicy == ErrorPolicyStop && len(r.errs) > 0 {
+		return
+	}
+	r.errs = append(r.errs, &TreeEntryError{name, err})
+	if r.tree.errorPolicy == ErrorPolicyStop {
+		r.cancel()
+	}
+}
+
+func (r *treeRun) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.errs) == 0 {
+		return nil
+	}
+	if r.tree.errorPolicy == ErrorPolicyStop {
+		return r.errs[0]
+	}
+	return errors.Join(r.errs...)
+}
+
+// Helper for list dir, reports whether it succeeded
+func (r *treeRun) list(authToken string, name string) ([]adapter.FileResult, bool) {
+	var list []adapter.FileResult
+	ok := r.call(name, func(ctx context.Context) error {
+		var err error
+		list, err = r.tree.files.ListFiles(ctx, authToken, name)
+		return err
+	})
+	return list, ok
+}
+
+func (r *treeRun) removeDir(authToken string, name string) bool {
+	list, ok := r.list(authToken, name)
+	if !ok {
+		return false
+	}
+
+	var wg sync.WaitGroup
+	var failed atomic.Bool
+	for _, entry := range list {
+		child := path.Join(name, entry.Name)
+		r.spawn(&wg, func() {
+			var ok bool
+			if entry.IsDir {
+				ok = r.removeDir(authToken, child)
+			} else {
+				ok = r.call(child, func(ctx context.Context) error {
+					return r.tree.files.DeleteFile(ctx, authToken, child)
+				})
+			}
+			if !ok {
+				failed.Store(true)
+			}
+		})
+	}
+	wg.Wait()
+
+	// Keep the dir if any child is left
+	if failed.Load() {
+		return false
+	}
+	return r.call(name, func(ctx context.Context) error {
+		return r.tree.files.DeleteDir(ctx, authToken, name)
+	})
+}
+
+func (r *treeRun) copyDir(authToken string, src string, dst string) {
+	list, ok := r.list(authToken, src)
+	if !ok {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, entry := range list {
+		srcChild, dstChild := path.Join(src, entry.Name), path.Join(dst, entry.Name)
+		r.spawn(&wg, func() {
+			if !entry.IsDir {
+				r.copyFile(authToken, srcChild, dstChild)
+				return
+			}
+			ok := r.call(dstChild, func(ctx context.Context) error {
+				err := r.tree.files.CreateDir(ctx, authToken, dstChild)
+				if errors.Is(err, adapter.ErrDirExist) {
+					return nil
+				}
+				return err
+			})
+			if ok {
+				r.copyDir(authToken, srcChild, dstChild)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func (r *treeRun) copyFile(authToken string, src string, dst string) {
+	r.call(src, func(ctx context.Context) error {
+		// Server-side
+		if !r.tree.clientSideCopy {
+			return r.tree.files.CopyFile(ctx, authToken, adapter.CopyFileData{
+				SrcPath:   src,
+				DstPath:   dst,
+				Overwrite: adapter.OverwriteFail,
+			})
+		}
+
+		// Client-side
+		data, err := r.tree.files.GetFile(ctx, authToken, src)
+		if err != nil {
+			return err
+		}
+		err = r.tree.files.CreateFile(ctx, authToken, adapter.CreateFileData{
+			Path: path.Dir(dst),
+			File: bytes.NewReader(data),
+			Name: path.Base(dst),
+		})
+		if errors.Is(err, adapter.ErrFileExist) {
+			return adapter.ErrFileDstExist
+		}
+		return err
+	})
+}
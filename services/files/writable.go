@@ -0,0 +1,145 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+
+	adapter "go.microcore.dev/sdk/services/files/repository/http"
+)
+
+// WritableFS is a filesystem that can be modified. Names follow the
+// fs.ValidPath rules. It is implemented by FS (files service), LocalFS
+// (local disk) and MemFS (memory).
+type WritableFS interface {
+	fs.FS
+	// Create creates or replaces the file, the parent dir must exist.
+	// The content is stored on Close.
+	Create(name string) (io.WriteCloser, error)
+	MkdirAll(name string) error
+	Rename(oldName string, newName string) error
+	// RemoveAll removes the name and any children it contains,
+	// a missing name is not an error.
+	RemoveAll(name string) error
+	Stat(name string) (fs.FileInfo, error)
+}
+
+func (f *FS) Create(name string) (io.WriteCloser, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+
+	// Check parent dir
+	parent, err := f.stat("create", path.Dir(name))
+	if err != nil {
+		return nil, err
+	}
+	if !parent.IsDir() {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &fileWriter{fs: f, name: name}, nil
+}
+
+func (f *FS) MkdirAll(name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if err := f.tree().MkdirAll(f.ctx, f.authToken, f.remote(name)); err != nil {
+		return pathError("mkdir", name, err)
+	}
+	return nil
+}
+
+func (f *FS) Rename(oldName string, newName string) error {
+	if !fs.ValidPath(oldName) || !fs.ValidPath(newName) || oldName == "." || newName == "." {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrInvalid}
+	}
+
+	info, err := f.stat("rename", oldName)
+	if err != nil {
+		return err
+	}
+
+	// Dirs
+	if info.IsDir() {
+		err = f.files.RenameDir(f.ctx, f.authToken, adapter.RenameDirData{
+			OldPath: f.remote(oldName),
+			NewPath: f.remote(newName),
+		})
+		if err != nil {
+			return pathError("rename", oldName, err)
+		}
+		return nil
+	}
+
+	// Files
+	err = f.files.RenameFile(f.ctx, f.authToken, adapter.RenameFileData{
+		OldPath: f.remote(oldName),
+		NewPath: f.remote(newName),
+	})
+	if err != nil {
+		return pathError("rename", oldName, err)
+	}
+	return nil
+}
+
+func (f *FS) RemoveAll(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "removeall", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if err := f.tree().RemoveAll(f.ctx, f.authToken, f.remote(name)); err != nil {
+		return pathError("removeall", name, err)
+	}
+	return nil
+}
+
+// Helper for build tree operations over the FS files adapter
+func (f *FS) tree() *Tree {
+	return NewTree(&TreeConfig{Files: f.files})
+}
+
+// File writer
+
+type fileWriter struct {
+	fs     *FS
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, &fs.PathError{Op: "write", Path: w.name, Err: fs.ErrClosed}
+	}
+	return w.buf.Write(p)
+}
+
+func (w *fileWriter) Close() error {
+	if w.closed {
+		return &fs.PathError{Op: "close", Path: w.name, Err: fs.ErrClosed}
+	}
+	w.closed = true
+
+	data := adapter.CreateFileData{
+		Path: w.fs.remote(path.Dir(w.name)),
+		File: bytes.NewReader(w.buf.Bytes()),
+		Name: path.Base(w.name),
+	}
+	err := w.fs.files.CreateFile(w.fs.ctx, w.fs.authToken, data)
+
+	// Replace existing file, keeping it if the upload fails
+	if errors.Is(err, adapter.ErrFileExist) {
+		data.File = bytes.NewReader(w.buf.Bytes())
+		err = adapter.ReplaceFile(w.fs.ctx, w.fs.files, w.fs.authToken, data)
+	}
+	if err != nil {
+		return pathError("close", w.name, err)
+	}
+
+	return nil
+}
@@ -9,28 +9,60 @@ import (
 
 	"go.microcore.dev/framework/transport/http"
 	"go.microcore.dev/framework/transport/http/client"
+	"go.microcore.dev/sdk/types"
 )
 
 type Config struct {
 	HttpClientManager            client.Manager
 	NotificationsServiceEndpoint string
+	// Max total size of the attachments of an email in bytes,
+	// EmailDefaultMaxAttachmentsSize if zero
+	MaxAttachmentsSize int64
+	// Locale of the last fallback of SendEmail, the default template
+	// variant if empty
+	DefaultLocale string
+	// Fallback locales per locale tried after its parents, for example
+	// {"uk": {"ru"}}
+	LocaleFallbacks map[string][]string
 }
 
 func New(config *Config) Interface {
+	maxAttachmentsSize := config.MaxAttachmentsSize
+	if maxAttachmentsSize <= 0 {
+		maxAttachmentsSize = EmailDefaultMaxAttachmentsSize
+	}
 	return &adapter{
 		config.HttpClientManager,
 		config.NotificationsServiceEndpoint,
+		maxAttachmentsSize,
+		config.DefaultLocale,
+		config.LocaleFallbacks,
 	}
 }
 
 type adapter struct {
 	httpClientManager            client.Manager
 	notificationsServiceEndpoint string
+	maxAttachmentsSize           int64
+	defaultLocale                string
+	localeFallbacks              map[string][]string
 }
 
 // Emails
 
 func (a *adapter) SendCustomEmail(ctx context.Context, authToken string, data SendCustomEmailData) (*SendCustomEmailResult, error) {
+	// Validate email
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Read attachments
+	attachments, err := a.readAttachments(data.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	data.Attachments = attachments
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.notificationsServiceEndpoint)
@@ -56,8 +88,8 @@ func (a *adapter) SendCustomEmail(ctx context.Context, authToken string, data Se
 		return nil, fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
 	}
 
-	// Check success status code
-	if res.StatusCode() == 201 {
+	// Check success status code, scheduled emails are accepted
+	if res.StatusCode() == 201 || res.StatusCode() == 202 {
 		var response SendCustomEmailResult
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
@@ -70,13 +102,18 @@ func (a *adapter) SendCustomEmail(ctx context.Context, authToken string, data Se
 
 	// Errors map
 	var errMap = map[string]error{
-		"bad_request:invalid_name":       ErrEmailInvalidName,
-		"bad_request:invalid_from_email": ErrEmailInvalidFromEmail,
-		"bad_request:invalid_from_name":  ErrEmailInvalidFromName,
-		"bad_request:invalid_subject":    ErrEmailInvalidSubject,
-		"bad_request:invalid_to_email":   ErrEmailInvalidToEmail,
-		"bad_request:invalid_html":       ErrEmailInvalidHtml,
-		"bad_request:invalid_text":       ErrEmailInvalidText,
+		"bad_request:invalid_name":          ErrEmailInvalidName,
+		"bad_request:invalid_from_email":    ErrEmailInvalidFromEmail,
+		"bad_request:invalid_from_name":     ErrEmailInvalidFromName,
+		"bad_request:invalid_subject":       ErrEmailInvalidSubject,
+		"bad_request:invalid_reply_to":      ErrEmailInvalidReplyTo,
+		"bad_request:invalid_send_at":       ErrEmailInvalidSendAt,
+		"bad_request:invalid_header":        ErrEmailInvalidHeader,
+		"bad_request:invalid_attachment":    ErrEmailInvalidAttachment,
+		"bad_request:attachments_too_large": ErrEmailAttachmentsTooLarge,
+		"bad_request:invalid_to_email":      ErrEmailInvalidToEmail,
+		"bad_request:invalid_html":          ErrEmailInvalidHtml,
+		"bad_request:invalid_text":          ErrEmailInvalidText,
 	}
 
 	// Parse errors
@@ -88,6 +125,30 @@ func (a *adapter) SendCustomEmail(ctx context.Context, authToken string, data Se
 }
 
 func (a *adapter) SendEmail(ctx context.Context, authToken string, data SendEmailData) (*SendEmailResult, error) {
+	// Validate email
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Read attachments
+	attachments, err := a.readAttachments(data.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	data.Attachments = attachments
+
+	// Resolve locale fallbacks
+	data.Locale = normalizeLocale(data.Locale)
+	if data.Locales != nil {
+		locales := make([]string, len(data.Locales))
+		for i, locale := range data.Locales {
+			locales[i] = normalizeLocale(locale)
+		}
+		data.Locales = locales
+	} else if data.Locale != "" || a.defaultLocale != "" {
+		data.Locales = EmailLocaleChain(data.Locale, a.localeFallbacks, a.defaultLocale)
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.notificationsServiceEndpoint)
@@ -113,8 +174,8 @@ func (a *adapter) SendEmail(ctx context.Context, authToken string, data SendEmai
 		return nil, fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
 	}
 
-	// Check success status code
-	if res.StatusCode() == 201 {
+	// Check success status code, scheduled emails are accepted
+	if res.StatusCode() == 201 || res.StatusCode() == 202 {
 		var response SendEmailResult
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
@@ -127,9 +188,17 @@ func (a *adapter) SendEmail(ctx context.Context, authToken string, data SendEmai
 
 	// Errors map
 	var errMap = map[string]error{
-		"bad_request:invalid_name":     ErrEmailInvalidName,
-		"bad_request:invalid_to_email": ErrEmailInvalidToEmail,
-		"bad_request:email_not_found":  ErrEmailNotFound,
+		"bad_request:invalid_name":            ErrEmailInvalidName,
+		"bad_request:invalid_reply_to":        ErrEmailInvalidReplyTo,
+		"bad_request:invalid_send_at":         ErrEmailInvalidSendAt,
+		"bad_request:invalid_header":          ErrEmailInvalidHeader,
+		"bad_request:invalid_attachment":      ErrEmailInvalidAttachment,
+		"bad_request:attachments_too_large":   ErrEmailAttachmentsTooLarge,
+		"bad_request:invalid_to_email":        ErrEmailInvalidToEmail,
+		"bad_request:email_not_found":         ErrEmailNotFound,
+		"bad_request:invalid_version":         ErrEmailInvalidVersion,
+		"bad_request:email_version_not_found": ErrEmailVersionNotFound,
+		"bad_request:invalid_locale":          ErrEmailInvalidLocale,
 	}
 
 	// Parse errors
@@ -140,7 +209,72 @@ func (a *adapter) SendEmail(ctx context.Context, authToken string, data SendEmai
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
-func (a *adapter) FilterEmails(ctx context.Context, authToken string, data FilterEmailsData) ([]FilterEmailsResult, error) {
+func (a *adapter) SendEmailBatch(ctx context.Context, authToken string, data SendEmailBatchData) (*SendEmailBatchResult, error) {
+	// Validate batch
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.notificationsServiceEndpoint)
+	url.WriteString("/notifications/emails/send/batch")
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
+	}
+
+	// Check success status code, scheduled emails are accepted
+	if res.StatusCode() == 201 || res.StatusCode() == 202 {
+		var response SendEmailBatchResult
+		if err := json.Unmarshal(res.Body(), &response); err != nil {
+			return nil, fmt.Errorf("error parsing response body: %v", err)
+		}
+		return &response, nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_name":       ErrEmailInvalidName,
+		"bad_request:invalid_recipients": ErrEmailBatchInvalidRecipients,
+		"bad_request:invalid_to_email":   ErrEmailInvalidToEmail,
+		"bad_request:invalid_send_at":    ErrEmailInvalidSendAt,
+		"bad_request:email_not_found":    ErrEmailNotFound,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+func (a *adapter) FilterEmails(ctx context.Context, authToken string, data FilterEmailsData) (*types.Page[FilterEmailsResult], error) {
+	// Validate filter
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.notificationsServiceEndpoint)
@@ -168,11 +302,11 @@ func (a *adapter) FilterEmails(ctx context.Context, authToken string, data Filte
 
 	// Check success status code
 	if res.StatusCode() == 200 {
-		var response []FilterEmailsResult
+		var response types.Page[FilterEmailsResult]
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
 		}
-		return response, nil
+		return &response, nil
 	}
 
 	// Response message
@@ -181,7 +315,12 @@ func (a *adapter) FilterEmails(ctx context.Context, authToken string, data Filte
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
-func (a *adapter) FilterEmailLogs(ctx context.Context, authToken string, data FilterEmailLogsData) ([]FilterEmailLogsResult, error) {
+func (a *adapter) FilterEmailLogs(ctx context.Context, authToken string, data FilterEmailLogsData) (*types.Page[FilterEmailLogsResult], error) {
+	// Validate filter
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.notificationsServiceEndpoint)
@@ -209,11 +348,11 @@ func (a *adapter) FilterEmailLogs(ctx context.Context, authToken string, data Fi
 
 	// Check success status code
 	if res.StatusCode() == 200 {
-		var response []FilterEmailLogsResult
+		var response types.Page[FilterEmailLogsResult]
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
 		}
-		return response, nil
+		return &response, nil
 	}
 
 	// Response message
@@ -222,6 +361,109 @@ func (a *adapter) FilterEmailLogs(ctx context.Context, authToken string, data Fi
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
+// CancelScheduledEmail cancels an email log with EmailStatusScheduled
+// status, its status becomes EmailStatusCanceled.
+func (a *adapter) CancelScheduledEmail(ctx context.Context, authToken string, id uint) error {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.notificationsServiceEndpoint)
+	url.WriteString("/notifications/emails/scheduled/")
+	url.WriteString(strconv.FormatUint(uint64(id), 10))
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodDelete),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 204 {
+		return nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:email_log_not_found": ErrEmailLogNotFound,
+		"bad_request:email_not_scheduled": ErrEmailLogNotScheduled,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+// AggregateEmailLogs counts the logs matching the filter per combination
+// of the GroupBy keys.
+func (a *adapter) AggregateEmailLogs(ctx context.Context, authToken string, data AggregateEmailLogsData) (*AggregateEmailLogsResult, error) {
+	// Validate aggregation
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.notificationsServiceEndpoint)
+	url.WriteString("/notifications/emails/log/aggregate")
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 200 {
+		var response AggregateEmailLogsResult
+		if err := json.Unmarshal(res.Body(), &response); err != nil {
+			return nil, fmt.Errorf("error parsing response body: %v", err)
+		}
+		return &response, nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_group_by":  ErrEmailLogInvalidGroupBy,
+		"bad_request:invalid_time_zone": ErrEmailLogInvalidTimeZone,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
 func (a *adapter) UpdateEmail(ctx context.Context, authToken string, id uint, data UpdateEmailData) error {
 	// Build url
 	var url strings.Builder
@@ -264,6 +506,7 @@ func (a *adapter) UpdateEmail(ctx context.Context, authToken string, id uint, da
 		"bad_request:invalid_from_email": ErrEmailInvalidFromEmail,
 		"bad_request:invalid_from_name":  ErrEmailInvalidFromName,
 		"bad_request:invalid_subject":    ErrEmailInvalidSubject,
+		"bad_request:invalid_locale":     ErrEmailInvalidLocale,
 		"bad_request:invalid_html":       ErrEmailInvalidHtml,
 		"bad_request:invalid_text":       ErrEmailInvalidText,
 		"bad_request:email_not_found":    ErrEmailNotFound,
@@ -363,6 +606,7 @@ func (a *adapter) CreateEmail(ctx context.Context, authToken string, data Create
 		"bad_request:invalid_from_email": ErrEmailInvalidFromEmail,
 		"bad_request:invalid_from_name":  ErrEmailInvalidFromName,
 		"bad_request:invalid_subject":    ErrEmailInvalidSubject,
+		"bad_request:invalid_locale":     ErrEmailInvalidLocale,
 		"bad_request:invalid_html":       ErrEmailInvalidHtml,
 		"bad_request:invalid_text":       ErrEmailInvalidText,
 		"bad_request:email_exist":        ErrEmailExist,
@@ -376,9 +620,280 @@ func (a *adapter) CreateEmail(ctx context.Context, authToken string, data Create
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
+// Email versions
+
+// FilterEmailVersions lists the versions of the email template, drafts
+// included.
+func (a *adapter) FilterEmailVersions(ctx context.Context, authToken string, emailId uint, data FilterEmailVersionsData) (*types.Page[EmailVersionResult], error) {
+	// Validate filter
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.notificationsServiceEndpoint)
+	url.WriteString("/notifications/emails/")
+	url.WriteString(strconv.FormatUint(uint64(emailId), 10))
+	url.WriteString("/versions/filter")
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 200 {
+		var response types.Page[EmailVersionResult]
+		if err := json.Unmarshal(res.Body(), &response); err != nil {
+			return nil, fmt.Errorf("error parsing response body: %v", err)
+		}
+		return &response, nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:email_not_found": ErrEmailNotFound,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+func (a *adapter) GetEmailVersion(ctx context.Context, authToken string, emailId uint, version uint) (*EmailVersionResult, error) {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.notificationsServiceEndpoint)
+	url.WriteString("/notifications/emails/")
+	url.WriteString(strconv.FormatUint(uint64(emailId), 10))
+	url.WriteString("/versions/")
+	url.WriteString(strconv.FormatUint(uint64(version), 10))
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodGet),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 200 {
+		var response EmailVersionResult
+		if err := json.Unmarshal(res.Body(), &response); err != nil {
+			return nil, fmt.Errorf("error parsing response body: %v", err)
+		}
+		return &response, nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:email_not_found":         ErrEmailNotFound,
+		"bad_request:email_version_not_found": ErrEmailVersionNotFound,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+// CreateEmailDraft saves a new draft version of the email template, sends
+// keep using the published version until the draft is published.
+func (a *adapter) CreateEmailDraft(ctx context.Context, authToken string, emailId uint, data CreateEmailDraftData) (*EmailVersionResult, error) {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.notificationsServiceEndpoint)
+	url.WriteString("/notifications/emails/")
+	url.WriteString(strconv.FormatUint(uint64(emailId), 10))
+	url.WriteString("/versions")
+
+	// Encode body json
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing request body: %v", err)
+	}
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestBody(body),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 201 {
+		var response EmailVersionResult
+		if err := json.Unmarshal(res.Body(), &response); err != nil {
+			return nil, fmt.Errorf("error parsing response body: %v", err)
+		}
+		return &response, nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:invalid_from_email": ErrEmailInvalidFromEmail,
+		"bad_request:invalid_from_name":  ErrEmailInvalidFromName,
+		"bad_request:invalid_subject":    ErrEmailInvalidSubject,
+		"bad_request:invalid_html":       ErrEmailInvalidHtml,
+		"bad_request:invalid_text":       ErrEmailInvalidText,
+		"bad_request:email_not_found":    ErrEmailNotFound,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+// PublishEmailVersion publishes the draft version, the previously
+// published version is archived.
+func (a *adapter) PublishEmailVersion(ctx context.Context, authToken string, emailId uint, version uint) error {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.notificationsServiceEndpoint)
+	url.WriteString("/notifications/emails/")
+	url.WriteString(strconv.FormatUint(uint64(emailId), 10))
+	url.WriteString("/versions/")
+	url.WriteString(strconv.FormatUint(uint64(version), 10))
+	url.WriteString("/publish")
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 204 {
+		return nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:email_not_found":         ErrEmailNotFound,
+		"bad_request:email_version_not_found": ErrEmailVersionNotFound,
+		"bad_request:email_version_not_draft": ErrEmailVersionNotDraft,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
+// RollbackEmail publishes the given earlier version again, the current
+// version is archived.
+func (a *adapter) RollbackEmail(ctx context.Context, authToken string, emailId uint, version uint) error {
+	// Build url
+	var url strings.Builder
+	url.WriteString(a.notificationsServiceEndpoint)
+	url.WriteString("/notifications/emails/")
+	url.WriteString(strconv.FormatUint(uint64(emailId), 10))
+	url.WriteString("/versions/")
+	url.WriteString(strconv.FormatUint(uint64(version), 10))
+	url.WriteString("/rollback")
+
+	// Send service request
+	res, err := a.httpClientManager.Request(
+		url.String(),
+		client.WithRequestMethod(http.MethodPost),
+		client.WithRequestContext(ctx),
+		client.WithRequestHeaders(
+			client.NewRequestHeader("Authorization", "Bearer "+authToken),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("service %s unavailable: %v", a.notificationsServiceEndpoint, err)
+	}
+
+	// Check success status code
+	if res.StatusCode() == 204 {
+		return nil
+	}
+
+	// Response message
+	errMessage := string(res.Body())
+
+	// Errors map
+	var errMap = map[string]error{
+		"bad_request:email_not_found":             ErrEmailNotFound,
+		"bad_request:email_version_not_found":     ErrEmailVersionNotFound,
+		"bad_request:email_version_not_published": ErrEmailVersionNotPublished,
+	}
+
+	// Parse errors
+	if err, ok := errMap[errMessage]; ok {
+		return err
+	}
+
+	return fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
+}
+
 // Folders
 
-func (a *adapter) FilterFolders(ctx context.Context, authToken string, data FilterEmailFoldersData) ([]FilterEmailFoldersResult, error) {
+func (a *adapter) FilterFolders(ctx context.Context, authToken string, data FilterEmailFoldersData) (*types.Page[FilterEmailFoldersResult], error) {
+	// Validate filter
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.notificationsServiceEndpoint)
@@ -406,11 +921,11 @@ func (a *adapter) FilterFolders(ctx context.Context, authToken string, data Filt
 
 	// Check success status code
 	if res.StatusCode() == 200 {
-		var response []FilterEmailFoldersResult
+		var response types.Page[FilterEmailFoldersResult]
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
 		}
-		return response, nil
+		return &response, nil
 	}
 
 	// Response message
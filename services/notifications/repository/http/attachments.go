@@ -0,0 +1,90 @@
+package adapter
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// Default max total size of the attachments of an email.
+	EmailDefaultMaxAttachmentsSize = 25 << 20
+)
+
+// Helper for validate attachments and read their readers within the size
+// limit, returns a copy of the attachments with content sources only
+func (a *adapter) readAttachments(attachments []EmailAttachment) ([]EmailAttachment, error) {
+	if len(attachments) == 0 {
+		return attachments, nil
+	}
+
+	result := make([]EmailAttachment, len(attachments))
+	remaining := a.maxAttachmentsSize
+	for i, attachment := range attachments {
+		if err := validateAttachment(attachment); err != nil {
+			return nil, err
+		}
+
+		// Read reader source
+		if attachment.Reader != nil {
+			content, err := io.ReadAll(io.LimitReader(attachment.Reader, remaining+1))
+			if err != nil {
+				return nil, fmt.Errorf("read attachment %s: %w", attachment.Filename, err)
+			}
+			attachment.Content = content
+			attachment.Reader = nil
+		}
+
+		if int64(len(attachment.Content)) > remaining {
+			return nil, ErrEmailAttachmentsTooLarge
+		}
+		remaining -= int64(len(attachment.Content))
+
+		// Detect content type
+		if attachment.ContentType == "" && attachment.FilePath == "" {
+			attachment.ContentType = mime.TypeByExtension(path.Ext(attachment.Filename))
+			if attachment.ContentType == "" {
+				attachment.ContentType = http.DetectContentType(attachment.Content)
+			}
+		}
+
+		result[i] = attachment
+	}
+
+	return result, nil
+}
+
+// Helper for validate attachment filename, content id and source
+func validateAttachment(attachment EmailAttachment) error {
+	if attachment.Filename == "" || strings.ContainsAny(attachment.Filename, "\r\n/\\") {
+		return ErrEmailInvalidAttachment
+	}
+	if strings.ContainsAny(attachment.ContentId, "<> \t\r\n") {
+		return ErrEmailInvalidAttachment
+	}
+	if attachment.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(attachment.ContentType); err != nil {
+			return ErrEmailInvalidAttachment
+		}
+	}
+
+	// Exactly one source
+	var sources int
+	if attachment.Content != nil {
+		sources++
+	}
+	if attachment.Reader != nil {
+		sources++
+	}
+	if attachment.FilePath != "" {
+		sources++
+	}
+	if sources != 1 {
+		return ErrEmailInvalidAttachment
+	}
+
+	return nil
+}
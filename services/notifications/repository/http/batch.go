@@ -0,0 +1,126 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// Max recipients of a single SendEmailBatch request.
+	EmailBatchMaxRecipients = 1000
+	// Default number of concurrent SendEmailBatch requests of SendEmailBatches.
+	EmailBatchDefaultConcurrency = 4
+)
+
+// Validate checks the schedule and recipients of the batch.
+func (d SendEmailBatchData) Validate() error {
+	if d.SendAt != nil && d.SendAt.IsZero() {
+		return ErrEmailInvalidSendAt
+	}
+	if len(d.Recipients) == 0 || len(d.Recipients) > EmailBatchMaxRecipients {
+		return ErrEmailBatchInvalidRecipients
+	}
+	for _, recipient := range d.Recipients {
+		if !validEmailAddress(EmailAddress{Email: recipient.ToEmail}) {
+			return &EmailAddressError{EmailRecipientTo, recipient.ToEmail, ErrEmailInvalidToEmail}
+		}
+	}
+	return nil
+}
+
+type EmailBatchConfig struct {
+	// Recipients per request, EmailBatchMaxRecipients if zero
+	ChunkSize int
+	// Concurrent requests, EmailBatchDefaultConcurrency if zero
+	Concurrency int
+}
+
+// EmailBatchChunkError is the error of a failed chunk of SendEmailBatches,
+// covering the recipients [Offset, Offset+Count) of the input.
+type EmailBatchChunkError struct {
+	Offset int
+	Count  int
+	Err    error
+}
+
+func (e *EmailBatchChunkError) Error() string {
+	return fmt.Sprintf("recipients %d-%d: %v", e.Offset, e.Offset+e.Count-1, e.Err)
+}
+
+func (e *EmailBatchChunkError) Unwrap() error {
+	return e.Err
+}
+
+// SendEmailBatchesResult holds the results of the sent chunks in input
+// order and the errors of the failed chunks.
+type SendEmailBatchesResult struct {
+	Batches []SendEmailBatchResult
+	Failed  []EmailBatchChunkError
+}
+
+// SendEmailBatches splits the recipients into chunks and sends them with
+// concurrent SendEmailBatch requests. Failed chunks do not stop the others,
+// the returned error joins their EmailBatchChunkError.
+func SendEmailBatches(ctx context.Context, notifications Interface, authToken string, data SendEmailBatchData, config *EmailBatchConfig) (*SendEmailBatchesResult, error) {
+	if config == nil {
+		config = &EmailBatchConfig{}
+	}
+	chunkSize := config.ChunkSize
+	if chunkSize <= 0 || chunkSize > EmailBatchMaxRecipients {
+		chunkSize = EmailBatchMaxRecipients
+	}
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = EmailBatchDefaultConcurrency
+	}
+	if len(data.Recipients) == 0 {
+		return nil, ErrEmailBatchInvalidRecipients
+	}
+
+	chunks := (len(data.Recipients) + chunkSize - 1) / chunkSize
+	batches := make([]*SendEmailBatchResult, chunks)
+	chunkErrs := make([]*EmailBatchChunkError, chunks)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range chunks {
+		offset := i * chunkSize
+		chunk := data
+		chunk.Recipients = data.Recipients[offset:min(offset+chunkSize, len(data.Recipients))]
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			chunkErrs[i] = &EmailBatchChunkError{offset, len(chunk.Recipients), ctx.Err()}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			batch, err := notifications.SendEmailBatch(ctx, authToken, chunk)
+			if err != nil {
+				chunkErrs[i] = &EmailBatchChunkError{offset, len(chunk.Recipients), err}
+				return
+			}
+			batches[i] = batch
+		}()
+	}
+	wg.Wait()
+
+	// Collect in input order
+	result := &SendEmailBatchesResult{}
+	var errs []error
+	for i := range chunks {
+		if chunkErrs[i] != nil {
+			result.Failed = append(result.Failed, *chunkErrs[i])
+			errs = append(errs, chunkErrs[i])
+			continue
+		}
+		result.Batches = append(result.Batches, *batches[i])
+	}
+
+	return result, errors.Join(errs...)
+}
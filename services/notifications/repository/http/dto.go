@@ -2,45 +2,139 @@ package adapter
 
 import (
 	"encoding/json"
+	"io"
 	"time"
+
+	"go.microcore.dev/sdk/types"
 )
 
 // Data
 
+// Email address with an optional display name
+type EmailAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Email attachment with exactly one source: Content, Reader or the path
+// of a file stored in the files service. Readers are read before sending,
+// file sizes are checked by the service. A ContentId makes the attachment
+// inline, referenced from html as "cid:<ContentId>".
+type EmailAttachment struct {
+	Filename string `json:"filename"`
+	// Detected from the content or filename if empty
+	ContentType string    `json:"content_type,omitempty"`
+	ContentId   string    `json:"content_id,omitempty"`
+	Content     []byte    `json:"content,omitempty"`
+	Reader      io.Reader `json:"-"`
+	FilePath    string    `json:"file_path,omitempty"`
+}
+
+// ToEmail is a single recipient without a display name, it can be
+// combined with To. Headers must not set the address, subject or content
+// headers.
 type SendCustomEmailData struct {
-	Name      string `json:"name"`
-	FromEmail string `json:"from_email"`
-	FromName  string `json:"from_name"`
-	Subject   string `json:"subject"`
-	ToEmail   string `json:"to_email"`
-	Html      string `json:"html"`
-	Text      string `json:"text"`
+	Name        string            `json:"name"`
+	FromEmail   string            `json:"from_email"`
+	FromName    string            `json:"from_name"`
+	Subject     string            `json:"subject"`
+	ToEmail     string            `json:"to_email,omitempty"`
+	To          []EmailAddress    `json:"to,omitempty"`
+	Cc          []EmailAddress    `json:"cc,omitempty"`
+	Bcc         []EmailAddress    `json:"bcc,omitempty"`
+	ReplyTo     *EmailAddress     `json:"reply_to,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []EmailAttachment `json:"attachments,omitempty"`
+	Html        string            `json:"html"`
+	Text        string            `json:"text"`
+	// Send at the time instead of immediately, see EmailStatusScheduled
+	SendAt *time.Time `json:"send_at,omitempty"`
 }
 
 type SendEmailData struct {
-	Name    string           `json:"name"`
+	Name        string            `json:"name"`
+	ToEmail     string            `json:"to_email,omitempty"`
+	To          []EmailAddress    `json:"to,omitempty"`
+	Cc          []EmailAddress    `json:"cc,omitempty"`
+	Bcc         []EmailAddress    `json:"bcc,omitempty"`
+	ReplyTo     *EmailAddress     `json:"reply_to,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []EmailAttachment `json:"attachments,omitempty"`
+	Vars        *json.RawMessage  `json:"vars"`
+	// Send at the time instead of immediately, see EmailStatusScheduled
+	SendAt *time.Time `json:"send_at,omitempty"`
+	// Pin a template version, the published version if nil
+	Version *uint `json:"version,omitempty"`
+	// Preferred locale of the template variant. The first existing variant
+	// of Locales is sent, the default variant if none exists. Locales are
+	// resolved with EmailLocaleChain from the config if nil.
+	Locale  string   `json:"locale,omitempty"`
+	Locales []string `json:"locales,omitempty"`
+}
+
+type SendEmailBatchData struct {
+	Name       string                `json:"name"`
+	Recipients []EmailBatchRecipient `json:"recipients"`
+	// Send at the time instead of immediately, see EmailStatusScheduled
+	SendAt *time.Time `json:"send_at,omitempty"`
+}
+
+// Recipient of a batch email with its own template vars
+type EmailBatchRecipient struct {
 	ToEmail string           `json:"to_email"`
 	Vars    *json.RawMessage `json:"vars"`
 }
 
+// A nil FolderId value is the root folder, In[*uint](nil, &id) matches
+// the root or folder id.
 type FilterEmailsData struct {
-	Id         *[]uint   `json:"id,omitempty"`
-	Name       *[]string `json:"name,omitempty"`
-	FolderId   *[]*uint  `json:"folder_id,omitempty"`
-	FromEmail  *[]string `json:"from_email,omitempty"`
-	FromName   *[]string `json:"from_name,omitempty"`
-	Subject    *[]string `json:"subject,omitempty"`
-	SystemFlag *bool     `json:"system_flag,omitempty"`
+	Id         *types.Predicate[uint]      `json:"id,omitempty"`
+	Name       *types.Predicate[string]    `json:"name,omitempty"`
+	FolderId   *types.Predicate[*uint]     `json:"folder_id,omitempty"`
+	FromEmail  *types.Predicate[string]    `json:"from_email,omitempty"`
+	FromName   *types.Predicate[string]    `json:"from_name,omitempty"`
+	Subject    *types.Predicate[string]    `json:"subject,omitempty"`
+	Locale     *types.Predicate[string]    `json:"locale,omitempty"`
+	SystemFlag *bool                       `json:"system_flag,omitempty"`
+	Created    *types.Predicate[time.Time] `json:"created,omitempty"`
+	Updated    *types.Predicate[time.Time] `json:"updated,omitempty"`
+	types.PageParams
 }
 
 type FilterEmailLogsData struct {
-	Id        *[]uint   `json:"id,omitempty"`
-	Name      *[]string `json:"name,omitempty"`
-	FromEmail *[]string `json:"from_email,omitempty"`
-	FromName  *[]string `json:"from_name,omitempty"`
-	ToEmail   *[]string `json:"to_email,omitempty"`
-	Status    *[]string `json:"status,omitempty"`
-	MessageId *[]string `json:"message_id,omitempty"`
+	Id        *types.Predicate[uint]      `json:"id,omitempty"`
+	Name      *types.Predicate[string]    `json:"name,omitempty"`
+	FromEmail *types.Predicate[string]    `json:"from_email,omitempty"`
+	FromName  *types.Predicate[string]    `json:"from_name,omitempty"`
+	Subject   *types.Predicate[string]    `json:"subject,omitempty"`
+	ToEmail   *types.Predicate[string]    `json:"to_email,omitempty"`
+	Status    *types.Predicate[string]    `json:"status,omitempty"`
+	MessageId *types.Predicate[string]    `json:"message_id,omitempty"`
+	Version   *types.Predicate[uint]      `json:"version,omitempty"`
+	SendAt    *types.Predicate[time.Time] `json:"send_at,omitempty"`
+	Created   *types.Predicate[time.Time] `json:"created,omitempty"`
+	types.PageParams
+}
+
+// Group keys of email log aggregations
+type EmailLogGroup string
+
+const (
+	EmailLogGroupStatus EmailLogGroup = "status"
+	EmailLogGroupName   EmailLogGroup = "name"
+	// Calendar day of Created in the aggregation time zone
+	EmailLogGroupDay EmailLogGroup = "day"
+)
+
+type AggregateEmailLogsData struct {
+	Name      *types.Predicate[string]    `json:"name,omitempty"`
+	FromEmail *types.Predicate[string]    `json:"from_email,omitempty"`
+	ToEmail   *types.Predicate[string]    `json:"to_email,omitempty"`
+	Status    *types.Predicate[string]    `json:"status,omitempty"`
+	Created   *types.Predicate[time.Time] `json:"created,omitempty"`
+	GroupBy   []EmailLogGroup             `json:"group_by"`
+	// IANA time zone of day groups, UTC if nil
+	TimeZone *string `json:"time_zone,omitempty"`
 }
 
 type UpdateEmailData struct {
@@ -49,28 +143,55 @@ type UpdateEmailData struct {
 	FromEmail   *string `json:"from_email,omitempty"`
 	FromName    *string `json:"from_name,omitempty"`
 	Subject     *string `json:"subject,omitempty"`
+	Locale      *string `json:"locale,omitempty"`
 	Html        *string `json:"html,omitempty"`
 	Text        *string `json:"text,omitempty"`
 	Description *string `json:"description,omitempty"`
 }
 
+// Templates are unique by Name and Locale, an empty Locale is the default
+// variant.
 type CreateEmailData struct {
 	Name        string `json:"name"`
 	FolderId    *uint  `json:"folder_id"`
 	FromEmail   string `json:"from_email"`
 	FromName    string `json:"from_name"`
 	Subject     string `json:"subject"`
+	Locale      string `json:"locale"`
 	Html        string `json:"html"`
 	Text        string `json:"text"`
 	Description string `json:"description"`
 	SystemFlag  bool   `json:"system_flag"`
 }
 
+type FilterEmailVersionsData struct {
+	Version *types.Predicate[uint]      `json:"version,omitempty"`
+	Status  *types.Predicate[string]    `json:"status,omitempty"`
+	Created *types.Predicate[time.Time] `json:"created,omitempty"`
+	types.PageParams
+}
+
+// Content of a new draft version, fields that are nil are copied from the
+// latest version.
+type CreateEmailDraftData struct {
+	FromEmail   *string `json:"from_email,omitempty"`
+	FromName    *string `json:"from_name,omitempty"`
+	Subject     *string `json:"subject,omitempty"`
+	Html        *string `json:"html,omitempty"`
+	Text        *string `json:"text,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// A nil ParentId value is the root folder, In[*uint](nil, &id) matches
+// the root or folder id.
 type FilterEmailFoldersData struct {
-	Id         *[]uint   `json:"id,omitempty"`
-	ParentId   *[]*uint  `json:"parent_id,omitempty"`
-	Name       *[]string `json:"name,omitempty"`
-	SystemFlag *bool     `json:"system_flag,omitempty"`
+	Id         *types.Predicate[uint]      `json:"id,omitempty"`
+	ParentId   *types.Predicate[*uint]     `json:"parent_id,omitempty"`
+	Name       *types.Predicate[string]    `json:"name,omitempty"`
+	SystemFlag *bool                       `json:"system_flag,omitempty"`
+	Created    *types.Predicate[time.Time] `json:"created,omitempty"`
+	Updated    *types.Predicate[time.Time] `json:"updated,omitempty"`
+	types.PageParams
 }
 
 type UpdateEmailFolderData struct {
@@ -89,33 +210,86 @@ type CreateEmailFolderData struct {
 // Results
 
 type SendCustomEmailResult struct {
-	Id        uint      `json:"id"`
-	Name      string    `json:"name"`
-	FromEmail string    `json:"from_email"`
-	FromName  string    `json:"from_name"`
-	Subject   string    `json:"subject"`
-	ToEmail   string    `json:"to_email"`
-	Html      string    `json:"html"`
-	Text      string    `json:"text"`
-	Status    string    `json:"status"`
-	MessageId *string   `json:"message_id"`
-	Errors    *string   `json:"errors"`
-	Created   time.Time `json:"created"`
+	Id         uint                   `json:"id"`
+	Name       string                 `json:"name"`
+	FromEmail  string                 `json:"from_email"`
+	FromName   string                 `json:"from_name"`
+	Subject    string                 `json:"subject"`
+	ToEmail    string                 `json:"to_email"`
+	Html       string                 `json:"html"`
+	Text       string                 `json:"text"`
+	Status     string                 `json:"status"`
+	MessageId  *string                `json:"message_id"`
+	Errors     *string                `json:"errors"`
+	Recipients []EmailRecipientResult `json:"recipients"`
+	SendAt     *time.Time             `json:"send_at"`
+	Created    time.Time              `json:"created"`
 }
 
 type SendEmailResult struct {
-	Id        uint      `json:"id"`
-	Name      string    `json:"name"`
-	FromEmail string    `json:"from_email"`
-	FromName  string    `json:"from_name"`
-	Subject   string    `json:"subject"`
-	ToEmail   string    `json:"to_email"`
-	Html      string    `json:"html"`
-	Text      string    `json:"text"`
-	Status    string    `json:"status"`
-	MessageId *string   `json:"message_id"`
-	Errors    *string   `json:"errors"`
-	Created   time.Time `json:"created"`
+	Id         uint                   `json:"id"`
+	Name       string                 `json:"name"`
+	FromEmail  string                 `json:"from_email"`
+	FromName   string                 `json:"from_name"`
+	Subject    string                 `json:"subject"`
+	ToEmail    string                 `json:"to_email"`
+	Html       string                 `json:"html"`
+	Text       string                 `json:"text"`
+	Status     string                 `json:"status"`
+	MessageId  *string                `json:"message_id"`
+	Errors     *string                `json:"errors"`
+	Recipients []EmailRecipientResult `json:"recipients"`
+	SendAt     *time.Time             `json:"send_at"`
+	Version    uint                   `json:"version"`
+	Locale     string                 `json:"locale"`
+	Created    time.Time              `json:"created"`
+}
+
+type SendEmailBatchResult struct {
+	BatchId string                     `json:"batch_id"`
+	Items   []SendEmailBatchItemResult `json:"items"`
+}
+
+// Result of a batch recipient, Id is the email log id if it was created
+type SendEmailBatchItemResult struct {
+	ToEmail string  `json:"to_email"`
+	Id      *uint   `json:"id"`
+	Status  string  `json:"status"`
+	Errors  *string `json:"errors"`
+}
+
+// Statuses of scheduled emails
+const (
+	EmailStatusScheduled = "scheduled"
+	EmailStatusCanceled  = "canceled"
+)
+
+// Statuses of email template versions. A template has at most one
+// published version, the Version of FilterEmailsResult, publishing
+// archives the previous one.
+const (
+	EmailVersionDraft     = "draft"
+	EmailVersionPublished = "published"
+	EmailVersionArchived  = "archived"
+)
+
+// Kind of an email recipient
+type EmailRecipientKind string
+
+const (
+	EmailRecipientTo  EmailRecipientKind = "to"
+	EmailRecipientCc  EmailRecipientKind = "cc"
+	EmailRecipientBcc EmailRecipientKind = "bcc"
+)
+
+// Delivery result of a single recipient
+type EmailRecipientResult struct {
+	Email     string             `json:"email"`
+	Name      string             `json:"name"`
+	Kind      EmailRecipientKind `json:"kind"`
+	Status    string             `json:"status"`
+	MessageId *string            `json:"message_id"`
+	Errors    *string            `json:"errors"`
 }
 
 type FilterEmailsResult struct {
@@ -125,27 +299,46 @@ type FilterEmailsResult struct {
 	FromEmail   string    `json:"from_email"`
 	FromName    string    `json:"from_name"`
 	Subject     string    `json:"subject"`
+	Locale      string    `json:"locale"`
 	Html        string    `json:"html"`
 	Text        string    `json:"text"`
 	Description string    `json:"description"`
 	SystemFlag  bool      `json:"system_flag"`
+	Version     uint      `json:"version"`
 	Updated     time.Time `json:"updated"`
 	Created     time.Time `json:"created"`
 }
 
 type FilterEmailLogsResult struct {
-	Id        uint      `json:"id"`
-	Name      string    `json:"name"`
-	FromEmail string    `json:"from_email"`
-	FromName  string    `json:"from_name"`
-	Subject   string    `json:"subject"`
-	ToEmail   string    `json:"to_email"`
-	Html      string    `json:"html"`
-	Text      string    `json:"text"`
-	Status    string    `json:"status"`
-	MessageId *string   `json:"message_id"`
-	Errors    *string   `json:"errors"`
-	Created   time.Time `json:"created"`
+	Id        uint       `json:"id"`
+	Name      string     `json:"name"`
+	FromEmail string     `json:"from_email"`
+	FromName  string     `json:"from_name"`
+	Subject   string     `json:"subject"`
+	ToEmail   string     `json:"to_email"`
+	Html      string     `json:"html"`
+	Text      string     `json:"text"`
+	Status    string     `json:"status"`
+	MessageId *string    `json:"message_id"`
+	Errors    *string    `json:"errors"`
+	SendAt    *time.Time `json:"send_at"`
+	Version   *uint      `json:"version"`
+	Locale    *string    `json:"locale"`
+	Created   time.Time  `json:"created"`
+}
+
+type AggregateEmailLogsResult struct {
+	Total  uint                      `json:"total"`
+	Groups []AggregateEmailLogsGroup `json:"groups"`
+}
+
+// Count of logs of a group, keys not grouped by are nil. Day is formatted
+// as "2006-01-02".
+type AggregateEmailLogsGroup struct {
+	Status *string `json:"status"`
+	Name   *string `json:"name"`
+	Day    *string `json:"day"`
+	Count  uint    `json:"count"`
 }
 
 type CreateEmailResult struct {
@@ -155,6 +348,7 @@ type CreateEmailResult struct {
 	FromEmail   string    `json:"from_email"`
 	FromName    string    `json:"from_name"`
 	Subject     string    `json:"subject"`
+	Locale      string    `json:"locale"`
 	Html        string    `json:"html"`
 	Text        string    `json:"text"`
 	Description string    `json:"description"`
@@ -163,6 +357,21 @@ type CreateEmailResult struct {
 	Created     time.Time `json:"created"`
 }
 
+// Email template version, Published is nil for drafts
+type EmailVersionResult struct {
+	EmailId     uint       `json:"email_id"`
+	Version     uint       `json:"version"`
+	Status      string     `json:"status"`
+	FromEmail   string     `json:"from_email"`
+	FromName    string     `json:"from_name"`
+	Subject     string     `json:"subject"`
+	Html        string     `json:"html"`
+	Text        string     `json:"text"`
+	Description string     `json:"description"`
+	Published   *time.Time `json:"published"`
+	Created     time.Time  `json:"created"`
+}
+
 type FilterEmailFoldersResult struct {
 	Id          uint      `json:"id"`
 	ParentId    *uint     `json:"parent_id"`
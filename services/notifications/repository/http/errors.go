@@ -10,10 +10,33 @@ var (
 	ErrEmailInvalidFromName  = errors.New(errors.ErrBadRequest, "invalid_from_name")
 	ErrEmailInvalidSubject   = errors.New(errors.ErrBadRequest, "invalid_subject")
 	ErrEmailInvalidToEmail   = errors.New(errors.ErrBadRequest, "invalid_to_email")
+	ErrEmailInvalidReplyTo   = errors.New(errors.ErrBadRequest, "invalid_reply_to")
+	ErrEmailInvalidHeader    = errors.New(errors.ErrBadRequest, "invalid_header")
 	ErrEmailInvalidHtml      = errors.New(errors.ErrBadRequest, "invalid_html")
 	ErrEmailInvalidText      = errors.New(errors.ErrBadRequest, "invalid_text")
 	ErrEmailNotFound         = errors.New(errors.ErrBadRequest, "email_not_found")
 	ErrEmailExist            = errors.New(errors.ErrBadRequest, "email_exist")
+	ErrEmailInvalidSendAt    = errors.New(errors.ErrBadRequest, "invalid_send_at")
+	ErrEmailInvalidLocale    = errors.New(errors.ErrBadRequest, "invalid_locale")
+	// Templates
+	ErrEmailInvalidTemplate   = errors.New(errors.ErrBadRequest, "invalid_template")
+	ErrEmailInvalidVars       = errors.New(errors.ErrBadRequest, "invalid_vars")
+	ErrEmailTemplateAmbiguous = errors.New(errors.ErrBadRequest, "ambiguous_template")
+	// Versions
+	ErrEmailInvalidVersion      = errors.New(errors.ErrBadRequest, "invalid_version")
+	ErrEmailVersionNotFound     = errors.New(errors.ErrBadRequest, "email_version_not_found")
+	ErrEmailVersionNotDraft     = errors.New(errors.ErrBadRequest, "email_version_not_draft")
+	ErrEmailVersionNotPublished = errors.New(errors.ErrBadRequest, "email_version_not_published")
+	// Batches
+	ErrEmailBatchInvalidRecipients = errors.New(errors.ErrBadRequest, "invalid_recipients")
+	// Attachments
+	ErrEmailInvalidAttachment   = errors.New(errors.ErrBadRequest, "invalid_attachment")
+	ErrEmailAttachmentsTooLarge = errors.New(errors.ErrBadRequest, "attachments_too_large")
+	// Email logs
+	ErrEmailLogNotFound        = errors.New(errors.ErrBadRequest, "email_log_not_found")
+	ErrEmailLogNotScheduled    = errors.New(errors.ErrBadRequest, "email_not_scheduled")
+	ErrEmailLogInvalidGroupBy  = errors.New(errors.ErrBadRequest, "invalid_group_by")
+	ErrEmailLogInvalidTimeZone = errors.New(errors.ErrBadRequest, "invalid_time_zone")
 	// Folders
 	ErrFolderInvalidParent = errors.New(errors.ErrBadRequest, "invalid_parent")
 	ErrFolderInvalidName   = errors.New(errors.ErrBadRequest, "invalid_name")
@@ -0,0 +1,76 @@
+package adapter
+
+import (
+	"slices"
+	"time"
+
+	"go.microcore.dev/sdk/types"
+)
+
+// Validate checks the predicates and page params of the filter.
+func (d FilterEmailsData) Validate() error {
+	return types.ValidateAll(d.Id, d.Name, d.FolderId, d.FromEmail, d.FromName, d.Subject, d.Locale, d.Created, d.Updated, d.PageParams)
+}
+
+// Validate checks the predicates and page params of the filter.
+func (d FilterEmailLogsData) Validate() error {
+	return types.ValidateAll(d.Id, d.Name, d.FromEmail, d.FromName, d.Subject, d.ToEmail, d.Status, d.MessageId, d.Version, d.SendAt, d.Created, d.PageParams)
+}
+
+// Validate checks the predicates and page params of the filter.
+func (d FilterEmailVersionsData) Validate() error {
+	return types.ValidateAll(d.Version, d.Status, d.Created, d.PageParams)
+}
+
+// Validate checks the predicates and page params of the filter.
+func (d FilterEmailFoldersData) Validate() error {
+	return types.ValidateAll(d.Id, d.ParentId, d.Name, d.Created, d.Updated, d.PageParams)
+}
+
+// Validate checks the predicates, group keys and time zone of the
+// aggregation.
+func (d AggregateEmailLogsData) Validate() error {
+	if err := types.ValidateAll(d.Name, d.FromEmail, d.ToEmail, d.Status, d.Created); err != nil {
+		return err
+	}
+	if len(d.GroupBy) == 0 {
+		return ErrEmailLogInvalidGroupBy
+	}
+	for i, group := range d.GroupBy {
+		switch group {
+		case EmailLogGroupStatus, EmailLogGroupName, EmailLogGroupDay:
+		default:
+			return ErrEmailLogInvalidGroupBy
+		}
+		if slices.Contains(d.GroupBy[:i], group) {
+			return ErrEmailLogInvalidGroupBy
+		}
+	}
+	if d.TimeZone != nil {
+		if _, err := time.LoadLocation(*d.TimeZone); err != nil || *d.TimeZone == "" {
+			return ErrEmailLogInvalidTimeZone
+		}
+	}
+	return nil
+}
+
+// Sum returns the counts per value of a group key, summed over the other
+// keys. Groups without the key are not counted.
+func (r *AggregateEmailLogsResult) Sum(group EmailLogGroup) map[string]uint {
+	sums := make(map[string]uint)
+	for _, g := range r.Groups {
+		var key *string
+		switch group {
+		case EmailLogGroupStatus:
+			key = g.Status
+		case EmailLogGroupName:
+			key = g.Name
+		case EmailLogGroupDay:
+			key = g.Day
+		}
+		if key != nil {
+			sums[*key] += g.Count
+		}
+	}
+	return sums
+}
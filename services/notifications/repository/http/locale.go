@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"slices"
+	"strings"
+)
+
+// EmailLocaleChain returns the locales tried for a template variant in
+// order: the locale and its parents ("pt-BR", "pt"), then the configured
+// fallbacks of each of them, then the default locale. Underscores are read
+// as dashes, duplicates are skipped.
+func EmailLocaleChain(locale string, fallbacks map[string][]string, defaultLocale string) []string {
+	var chain []string
+	var add func(locale string)
+	add = func(locale string) {
+		// Locale and its parents
+		var added []string
+		for locale = normalizeLocale(locale); locale != ""; {
+			if !slices.Contains(chain, locale) {
+				chain = append(chain, locale)
+				added = append(added, locale)
+			}
+			i := strings.LastIndex(locale, "-")
+			if i < 0 {
+				break
+			}
+			locale = locale[:i]
+		}
+		// Configured fallbacks
+		for _, locale := range added {
+			for _, fallback := range fallbacks[locale] {
+				add(fallback)
+			}
+		}
+	}
+	add(locale)
+	add(defaultLocale)
+
+	return chain
+}
+
+// Helper for read underscores of a locale as dashes, "pt_BR" is "pt-BR"
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(locale, "_", "-")
+}
+
+// Helper for check a BCP 47 like locale: a 2-8 letters language followed
+// by 1-8 alphanumeric subtags, separated by dashes
+func validLocale(locale string) bool {
+	for i, tag := range strings.Split(locale, "-") {
+		if len(tag) == 0 || len(tag) > 8 || (i == 0 && len(tag) < 2) {
+			return false
+		}
+		for j := 0; j < len(tag); j++ {
+			c := tag[j] | 0x20
+			if (c < 'a' || c > 'z') && (i == 0 || tag[j] < '0' || tag[j] > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -2,19 +2,30 @@ package adapter
 
 import (
 	"context"
+
+	"go.microcore.dev/sdk/types"
 )
 
 type Interface interface {
 	// Emails
 	SendCustomEmail(ctx context.Context, authToken string, data SendCustomEmailData) (*SendCustomEmailResult, error)
 	SendEmail(ctx context.Context, authToken string, data SendEmailData) (*SendEmailResult, error)
-	FilterEmails(ctx context.Context, authToken string, data FilterEmailsData) ([]FilterEmailsResult, error)
-	FilterEmailLogs(ctx context.Context, authToken string, data FilterEmailLogsData) ([]FilterEmailLogsResult, error)
+	SendEmailBatch(ctx context.Context, authToken string, data SendEmailBatchData) (*SendEmailBatchResult, error)
+	FilterEmails(ctx context.Context, authToken string, data FilterEmailsData) (*types.Page[FilterEmailsResult], error)
+	FilterEmailLogs(ctx context.Context, authToken string, data FilterEmailLogsData) (*types.Page[FilterEmailLogsResult], error)
+	CancelScheduledEmail(ctx context.Context, authToken string, id uint) error
+	AggregateEmailLogs(ctx context.Context, authToken string, data AggregateEmailLogsData) (*AggregateEmailLogsResult, error)
 	UpdateEmail(ctx context.Context, authToken string, id uint, data UpdateEmailData) error
 	DeleteEmail(ctx context.Context, authToken string, id uint) error
 	CreateEmail(ctx context.Context, authToken string, data CreateEmailData) (*CreateEmailResult, error)
+	// Email versions
+	FilterEmailVersions(ctx context.Context, authToken string, emailId uint, data FilterEmailVersionsData) (*types.Page[EmailVersionResult], error)
+	GetEmailVersion(ctx context.Context, authToken string, emailId uint, version uint) (*EmailVersionResult, error)
+	CreateEmailDraft(ctx context.Context, authToken string, emailId uint, data CreateEmailDraftData) (*EmailVersionResult, error)
+	PublishEmailVersion(ctx context.Context, authToken string, emailId uint, version uint) error
+	RollbackEmail(ctx context.Context, authToken string, emailId uint, version uint) error
 	// Folders
-	FilterFolders(ctx context.Context, authToken string, data FilterEmailFoldersData) ([]FilterEmailFoldersResult, error)
+	FilterFolders(ctx context.Context, authToken string, data FilterEmailFoldersData) (*types.Page[FilterEmailFoldersResult], error)
 	UpdateFolder(ctx context.Context, authToken string, id uint, data UpdateEmailFolderData) error
 	DeleteFolder(ctx context.Context, authToken string, id uint) error
 	CreateFolder(ctx context.Context, authToken string, data CreateEmailFolderData) (*CreateEmailFolderResult, error)
@@ -0,0 +1,142 @@
+package adapter
+
+import (
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+)
+
+// Headers set by the service that can not be overridden
+var reservedEmailHeaders = []string{
+	"Bcc", "Cc", "Content-Disposition", "Content-Transfer-Encoding",
+	"Content-Type", "Date", "From", "Message-Id", "Mime-Version",
+	"Reply-To", "Return-Path", "Sender", "Subject", "To",
+}
+
+// EmailAddressError reports the invalid address of a recipient, it
+// unwraps to ErrEmailInvalidToEmail or ErrEmailInvalidReplyTo.
+type EmailAddressError struct {
+	Kind  EmailRecipientKind
+	Email string
+	Err   error
+}
+
+func (e *EmailAddressError) Error() string {
+	return e.Err.Error() + ": " + string(e.Kind) + ": " + e.Email
+}
+
+func (e *EmailAddressError) Unwrap() error {
+	return e.Err
+}
+
+// ParseEmailAddress parses an RFC 5322 address like
+// "Jane Doe <jane@example.com>".
+func ParseEmailAddress(address string) (EmailAddress, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return EmailAddress{}, &EmailAddressError{EmailRecipientTo, address, ErrEmailInvalidToEmail}
+	}
+	return EmailAddress{parsed.Address, parsed.Name}, nil
+}
+
+// String formats the address as an RFC 5322 address.
+func (a EmailAddress) String() string {
+	return (&mail.Address{Name: a.Name, Address: a.Email}).String()
+}
+
+// Validate checks the schedule, locales, recipients, headers and
+// attachments of the email.
+func (d SendEmailData) Validate() error {
+	if d.SendAt != nil && d.SendAt.IsZero() {
+		return ErrEmailInvalidSendAt
+	}
+	for _, locale := range append([]string{d.Locale}, d.Locales...) {
+		if locale != "" && !validLocale(normalizeLocale(locale)) {
+			return ErrEmailInvalidLocale
+		}
+	}
+	if err := validateRecipients(d.ToEmail, d.To, d.Cc, d.Bcc, d.ReplyTo, d.Headers); err != nil {
+		return err
+	}
+	for _, attachment := range d.Attachments {
+		if err := validateAttachment(attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks the schedule, recipients, headers and attachments of
+// the email.
+func (d SendCustomEmailData) Validate() error {
+	if d.SendAt != nil && d.SendAt.IsZero() {
+		return ErrEmailInvalidSendAt
+	}
+	if err := validateRecipients(d.ToEmail, d.To, d.Cc, d.Bcc, d.ReplyTo, d.Headers); err != nil {
+		return err
+	}
+	for _, attachment := range d.Attachments {
+		if err := validateAttachment(attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Helper for validate recipients and custom headers
+func validateRecipients(toEmail string, to []EmailAddress, cc []EmailAddress, bcc []EmailAddress, replyTo *EmailAddress, headers map[string]string) error {
+	if toEmail == "" && len(to) == 0 {
+		return &EmailAddressError{EmailRecipientTo, "", ErrEmailInvalidToEmail}
+	}
+	if toEmail != "" && !validEmailAddress(EmailAddress{Email: toEmail}) {
+		return &EmailAddressError{EmailRecipientTo, toEmail, ErrEmailInvalidToEmail}
+	}
+
+	kinds := []EmailRecipientKind{EmailRecipientTo, EmailRecipientCc, EmailRecipientBcc}
+	for i, list := range [][]EmailAddress{to, cc, bcc} {
+		kind := kinds[i]
+		for _, address := range list {
+			if !validEmailAddress(address) {
+				return &EmailAddressError{kind, address.Email, ErrEmailInvalidToEmail}
+			}
+		}
+	}
+
+	if replyTo != nil && !validEmailAddress(*replyTo) {
+		return &EmailAddressError{"reply_to", replyTo.Email, ErrEmailInvalidReplyTo}
+	}
+
+	for name, value := range headers {
+		if !validHeaderName(name) || strings.ContainsAny(value, "\r\n") {
+			return ErrEmailInvalidHeader
+		}
+		if slices.Contains(reservedEmailHeaders, textproto.CanonicalMIMEHeaderKey(name)) {
+			return ErrEmailInvalidHeader
+		}
+	}
+
+	return nil
+}
+
+// Helper for check a bare address and a display name without line breaks
+func validEmailAddress(address EmailAddress) bool {
+	parsed, err := mail.ParseAddress(address.Email)
+	if err != nil || parsed.Address != address.Email {
+		return false
+	}
+	return !strings.ContainsAny(address.Name, "\r\n")
+}
+
+// Helper for check a header field name, printable ASCII without colon
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c <= ' ' || c >= 0x7f || c == ':' {
+			return false
+		}
+	}
+	return true
+}
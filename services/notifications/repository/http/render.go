@@ -0,0 +1,168 @@
+package adapter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"os"
+	texttemplate "text/template"
+)
+
+// RenderEmailResult is a locally rendered email template. Missing are the
+// template vars not set in the vars, rendered as "<no value>" in the
+// subject and text and empty in the html, Unused are the vars not
+// referenced by the template.
+type RenderEmailResult struct {
+	From    EmailAddress
+	Subject string
+	Html    string
+	Text    string
+	Missing []string
+	Unused  []string
+}
+
+// RenderEmail renders the subject, html and text of the template with the
+// json encoded vars like the service does, subject and text as text and
+// html with contextual escaping.
+func RenderEmail(template FilterEmailsResult, vars any) (*RenderEmailResult, error) {
+	declared, err := TemplateVars(template)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse vars
+	raw, err := json.Marshal(vars)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing vars: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, ErrEmailInvalidVars
+	}
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, ErrEmailInvalidVars
+	}
+
+	result := &RenderEmailResult{
+		From: EmailAddress{template.FromEmail, template.FromName},
+	}
+	result.Missing, result.Unused = compareVars(declared, fields)
+
+	// Render
+	if result.Subject, err = renderText(template.Name, template.Subject, data); err != nil {
+		return nil, err
+	}
+	if result.Text, err = renderText(template.Name, template.Text, data); err != nil {
+		return nil, err
+	}
+	if template.Html != "" {
+		tmpl, err := htmltemplate.New(template.Name).Parse(template.Html)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrEmailInvalidTemplate, err)
+		}
+		var html bytes.Buffer
+		if err := tmpl.Execute(&html, data); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrEmailInvalidTemplate, err)
+		}
+		result.Html = html.String()
+	}
+
+	return result, nil
+}
+
+// WriteEml writes the rendered email as a MIME message with a text and an
+// html alternative. The output is stable for the same input, so it can be
+// compared with a golden file.
+func (r *RenderEmailResult) WriteEml(w io.Writer) error {
+	// Derive the boundary from the content
+	sum := sha256.Sum256([]byte(r.Subject + r.Text + r.Html))
+	boundary := hex.EncodeToString(sum[:16])
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return err
+	}
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", r.Text},
+		{"text/html; charset=utf-8", r.Html},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := io.WriteString(qw, part.content); err != nil {
+			return err
+		}
+		if err := qw.Close(); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	// Write headers
+	var header bytes.Buffer
+	if r.From.Email != "" {
+		fmt.Fprintf(&header, "From: %s\r\n", r.From.String())
+	}
+	fmt.Fprintf(&header, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", r.Subject))
+	header.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&header, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", boundary)
+
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// SaveEml writes the rendered email to the .eml file at path.
+func (r *RenderEmailResult) SaveEml(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteEml(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Helper for render a plain text template
+func renderText(name string, text string, data map[string]any) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := texttemplate.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrEmailInvalidTemplate, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrEmailInvalidTemplate, err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,186 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template/parse"
+
+	"go.microcore.dev/sdk/types"
+)
+
+// EmailVarsError reports template vars missing in the vars and vars
+// unused by the template, it unwraps to ErrEmailInvalidVars.
+type EmailVarsError struct {
+	Missing []string
+	Unused  []string
+}
+
+func (e *EmailVarsError) Error() string {
+	var msg strings.Builder
+	msg.WriteString(ErrEmailInvalidVars.Error())
+	if len(e.Missing) > 0 {
+		msg.WriteString(": missing: ")
+		msg.WriteString(strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unused) > 0 {
+		msg.WriteString(": unused: ")
+		msg.WriteString(strings.Join(e.Unused, ", "))
+	}
+	return msg.String()
+}
+
+func (e *EmailVarsError) Unwrap() error {
+	return ErrEmailInvalidVars
+}
+
+// SendTypedEmail sends the template name to a single recipient with vars
+// encoded as json, so field names follow the json tags of T.
+func SendTypedEmail[T any](ctx context.Context, notifications Interface, authToken string, name string, toEmail string, vars T) (*SendEmailResult, error) {
+	raw, err := json.Marshal(vars)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing vars: %v", err)
+	}
+	message := json.RawMessage(raw)
+	return notifications.SendEmail(ctx, authToken, SendEmailData{
+		Name:    name,
+		ToEmail: toEmail,
+		Vars:    &message,
+	})
+}
+
+// TemplateVars returns the sorted top-level vars referenced by the subject,
+// html and text of the template, for example "user" for {{.user.name}}.
+// Templates use the Go template syntax.
+func TemplateVars(template FilterEmailsResult) ([]string, error) {
+	vars := make(map[string]bool)
+	for _, text := range []string{template.Subject, template.Html, template.Text} {
+		tree := parse.New(template.Name)
+		tree.Mode = parse.SkipFuncCheck
+		treeSet := make(map[string]*parse.Tree)
+		if _, err := tree.Parse(text, "", "", treeSet); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrEmailInvalidTemplate, err)
+		}
+		for _, t := range treeSet {
+			collectTemplateVars(t.Root, true, vars)
+		}
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// EmailTemplateVars looks up the locale variant of the template name and
+// returns its vars, an empty locale is the default variant. Returns
+// ErrEmailTemplateAmbiguous if more than one template matches.
+func EmailTemplateVars(ctx context.Context, notifications Interface, authToken string, name string, locale string) ([]string, error) {
+	page, err := notifications.FilterEmails(ctx, authToken, FilterEmailsData{
+		Name:   types.In(name),
+		Locale: types.In(normalizeLocale(locale)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch len(page.Items) {
+	case 0:
+		return nil, ErrEmailNotFound
+	case 1:
+		return TemplateVars(page.Items[0])
+	}
+	return nil, ErrEmailTemplateAmbiguous
+}
+
+// ValidateEmailVars checks the json encoded vars against the declared
+// template vars, returns an EmailVarsError on missing or unused vars.
+func ValidateEmailVars(declared []string, vars any) error {
+	raw, err := json.Marshal(vars)
+	if err != nil {
+		return fmt.Errorf("error parsing vars: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return ErrEmailInvalidVars
+	}
+
+	missing, unused := compareVars(declared, fields)
+	if len(missing) == 0 && len(unused) == 0 {
+		return nil
+	}
+
+	return &EmailVarsError{missing, unused}
+}
+
+// Helper for compare the declared template vars with the given vars
+func compareVars(declared []string, fields map[string]json.RawMessage) ([]string, []string) {
+	var missing, unused []string
+	for _, name := range declared {
+		if _, ok := fields[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	for name := range fields {
+		if !slices.Contains(declared, name) {
+			unused = append(unused, name)
+		}
+	}
+	slices.Sort(unused)
+	return missing, unused
+}
+
+// Helper for collect top-level vars of a template node. Dot is rebound to
+// another value inside range and with blocks, only $ refers to the vars there.
+func collectTemplateVars(node parse.Node, rootDot bool, vars map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectTemplateVars(child, rootDot, vars)
+		}
+	case *parse.ActionNode:
+		collectTemplateVars(n.Pipe, rootDot, vars)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectTemplateVars(cmd, rootDot, vars)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectTemplateVars(arg, rootDot, vars)
+		}
+	case *parse.FieldNode:
+		if rootDot {
+			vars[n.Ident[0]] = true
+		}
+	case *parse.ChainNode:
+		collectTemplateVars(n.Node, rootDot, vars)
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			vars[n.Ident[1]] = true
+		}
+	case *parse.IfNode:
+		collectTemplateVars(n.Pipe, rootDot, vars)
+		collectTemplateVars(n.List, rootDot, vars)
+		collectTemplateVars(n.ElseList, rootDot, vars)
+	case *parse.RangeNode:
+		collectTemplateVars(n.Pipe, rootDot, vars)
+		collectTemplateVars(n.List, false, vars)
+		collectTemplateVars(n.ElseList, rootDot, vars)
+	case *parse.WithNode:
+		collectTemplateVars(n.Pipe, rootDot, vars)
+		collectTemplateVars(n.List, false, vars)
+		collectTemplateVars(n.ElseList, rootDot, vars)
+	case *parse.TemplateNode:
+		collectTemplateVars(n.Pipe, rootDot, vars)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"go.microcore.dev/framework/transport/http"
 	"go.microcore.dev/framework/transport/http/client"
+	"go.microcore.dev/sdk/types"
 )
 
 type Config struct {
@@ -318,6 +319,7 @@ func (a *adapter) Signup(ctx context.Context, data SignupData) (*SignupResult, e
 	// Errors map
 	var errMap = map[string]error{
 		"bad_request:invalid_name":        ErrInvalidName,
+		"bad_request:invalid_locale":      ErrInvalidLocale,
 		"bad_request:invalid_username":    ErrInvalidUsername,
 		"bad_request:invalid_email":       ErrInvalidEmail,
 		"bad_request:invalid_password":    ErrInvalidPassword,
@@ -367,7 +369,12 @@ func (a *adapter) Profile(ctx context.Context, authToken string) (*ProfileResult
 	return nil, fmt.Errorf("unexpected response: status code: %d, message: %s", res.StatusCode(), errMessage)
 }
 
-func (a *adapter) FilterUsers(ctx context.Context, authToken string, data FilterUsersData) ([]FilterUsersResult, error) {
+func (a *adapter) FilterUsers(ctx context.Context, authToken string, data FilterUsersData) (*types.Page[FilterUsersResult], error) {
+	// Validate filter
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Build url
 	var url strings.Builder
 	url.WriteString(a.usersServiceEndpoint)
@@ -395,11 +402,11 @@ func (a *adapter) FilterUsers(ctx context.Context, authToken string, data Filter
 
 	// Check success status code
 	if res.StatusCode() == 200 {
-		var response []FilterUsersResult
+		var response types.Page[FilterUsersResult]
 		if err := json.Unmarshal(res.Body(), &response); err != nil {
 			return nil, fmt.Errorf("error parsing response body: %v", err)
 		}
-		return response, nil
+		return &response, nil
 	}
 
 	// Response message
@@ -446,6 +453,7 @@ func (a *adapter) UpdateUser(ctx context.Context, authToken string, id string, d
 	// Errors map
 	var errMap = map[string]error{
 		"bad_request:invalid_name":        ErrInvalidName,
+		"bad_request:invalid_locale":      ErrInvalidLocale,
 		"bad_request:invalid_username":    ErrInvalidUsername,
 		"bad_request:invalid_email":       ErrInvalidEmail,
 		"bad_request:invalid_roles":       ErrInvalidRoles,
@@ -545,6 +553,7 @@ func (a *adapter) CreateUser(ctx context.Context, authToken string, data CreateU
 	// Errors map
 	var errMap = map[string]error{
 		"bad_request:invalid_name":        ErrInvalidName,
+		"bad_request:invalid_locale":      ErrInvalidLocale,
 		"bad_request:invalid_username":    ErrInvalidUsername,
 		"bad_request:invalid_password":    ErrInvalidPassword,
 		"bad_request:invalid_email":       ErrInvalidEmail,
@@ -1,6 +1,10 @@
 package adapter
 
-import "time"
+import (
+	"time"
+
+	"go.microcore.dev/sdk/types"
+)
 
 // Data
 
@@ -47,16 +51,20 @@ type SignupData struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Name     string `json:"name"`
+	// Preferred locale, for example of emails
+	Locale string `json:"locale,omitempty"`
 }
 
 type FilterUsersData struct {
-	Id         *[]uint   `json:"id"`
-	Username   *[]string `json:"username"`
-	Email      *[]string `json:"email"`
-	Roles      *[]string `json:"roles"`
-	OtpSecret  *[]string `json:"otp_secret"`
-	Mfa        *bool     `json:"mfa"`
-	SystemFlag *bool     `json:"system_flag"`
+	Id         *types.Predicate[uint]      `json:"id"`
+	Username   *types.Predicate[string]    `json:"username"`
+	Email      *types.Predicate[string]    `json:"email"`
+	Roles      *types.Predicate[string]    `json:"roles"`
+	OtpSecret  *types.Predicate[string]    `json:"otp_secret"`
+	Mfa        *bool                       `json:"mfa"`
+	SystemFlag *bool                       `json:"system_flag"`
+	Created    *types.Predicate[time.Time] `json:"created"`
+	types.PageParams
 }
 
 type UpdateUserData struct {
@@ -65,6 +73,8 @@ type UpdateUserData struct {
 	Email      string   `json:"email"`
 	Roles      []string `json:"roles"`
 	SystemFlag bool     `json:"system_flag"`
+	// Unchanged if nil
+	Locale *string `json:"locale,omitempty"`
 }
 
 type CreateUserData struct {
@@ -73,6 +83,7 @@ type CreateUserData struct {
 	Password   string   `json:"password"`
 	Name       string   `json:"name"`
 	Roles      []string `json:"roles"`
+	Locale     string   `json:"locale,omitempty"`
 	Notify     bool     `json:"notify"`
 	SystemFlag bool     `json:"system_flag"`
 }
@@ -102,6 +113,7 @@ type SignupResult struct {
 	Email      string    `json:"email"`
 	Name       string    `json:"name"`
 	Roles      []string  `json:"roles"`
+	Locale     string    `json:"locale"`
 	Mfa        bool      `json:"mfa"`
 	SystemFlag bool      `json:"system_flag"`
 }
@@ -113,6 +125,7 @@ type ProfileResult struct {
 	Email      string    `json:"email"`
 	Name       string    `json:"name"`
 	Roles      []string  `json:"roles"`
+	Locale     string    `json:"locale"`
 	Mfa        bool      `json:"mfa"`
 	SystemFlag bool      `json:"system_flag"`
 	Device     string    `json:"device"`
@@ -125,6 +138,7 @@ type FilterUsersResult struct {
 	Email      string    `json:"email"`
 	Name       string    `json:"name"`
 	Roles      []string  `json:"roles"`
+	Locale     string    `json:"locale"`
 	Mfa        bool      `json:"mfa"`
 	SystemFlag bool      `json:"system_flag"`
 }
@@ -136,6 +150,7 @@ type CreateUserResult struct {
 	Email      string    `json:"email"`
 	Name       string    `json:"name"`
 	Roles      []string  `json:"roles"`
+	Locale     string    `json:"locale"`
 	Mfa        bool      `json:"mfa"`
 	SystemFlag bool      `json:"system_flag"`
 }
@@ -9,6 +9,7 @@ var (
 	ErrInvalidUsername    = errors.New(errors.ErrBadRequest, "invalid_username")
 	ErrInvalidEmail       = errors.New(errors.ErrBadRequest, "invalid_email")
 	ErrInvalidName        = errors.New(errors.ErrBadRequest, "invalid_name")
+	ErrInvalidLocale      = errors.New(errors.ErrBadRequest, "invalid_locale")
 	ErrExistEmail         = errors.New(errors.ErrBadRequest, "user_exist_email")
 	ErrExistUsername      = errors.New(errors.ErrBadRequest, "user_exist_username")
 	ErrMfaDisabled        = errors.New(errors.ErrBadRequest, "mfa_disabled")
@@ -0,0 +1,8 @@
+package adapter
+
+import "go.microcore.dev/sdk/types"
+
+// Validate checks the predicates and page params of the filter.
+func (d FilterUsersData) Validate() error {
+	return types.ValidateAll(d.Id, d.Username, d.Email, d.Roles, d.OtpSecret, d.Created, d.PageParams)
+}
@@ -2,6 +2,8 @@ package adapter
 
 import (
 	"context"
+
+	"go.microcore.dev/sdk/types"
 )
 
 type Interface interface {
@@ -12,7 +14,7 @@ type Interface interface {
 	Signin(ctx context.Context, data SigninData) (*SigninResult, error)
 	Signup(ctx context.Context, data SignupData) (*SignupResult, error)
 	Profile(ctx context.Context, authToken string) (*ProfileResult, error)
-	FilterUsers(ctx context.Context, authToken string, data FilterUsersData) ([]FilterUsersResult, error)
+	FilterUsers(ctx context.Context, authToken string, data FilterUsersData) (*types.Page[FilterUsersResult], error)
 	UpdateUser(ctx context.Context, authToken string, id string, data UpdateUserData) error
 	DeleteUser(ctx context.Context, authToken string, id uint) error
 	CreateUser(ctx context.Context, authToken string, data CreateUserData) (*CreateUserResult, error)
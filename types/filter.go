@@ -0,0 +1,184 @@
+package types
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"time"
+
+	"go.microcore.dev/framework/errors"
+)
+
+var (
+	ErrInvalidPredicate = errors.New(errors.ErrBadRequest, "invalid_predicate")
+)
+
+type PredicateOp string
+
+const (
+	OpEq       PredicateOp = "eq"
+	OpIn       PredicateOp = "in"
+	OpNotIn    PredicateOp = "not_in"
+	OpPrefix   PredicateOp = "prefix"
+	OpContains PredicateOp = "contains"
+	OpRange    PredicateOp = "range"
+	OpIsNull   PredicateOp = "is_null"
+	OpNotNull  PredicateOp = "not_null"
+)
+
+// Predicate is a typed condition on a single filter field, serialised as
+// {"op": "eq|prefix|contains", "value": v}, {"op": "in|not_in", "values": [v]},
+// {"op": "range", "from": v, "to": v} or {"op": "is_null|not_null"}.
+// Range bounds are inclusive, a nil bound is open. Use the builders
+// Eq, In, NotIn, Prefix, Contains, Range, Between, IsNull and NotNull.
+//
+// An "in" predicate is serialised as the plain list [v], the filter shape
+// of services without predicate support, and a plain list is read as "in".
+type Predicate[T any] struct {
+	Op     PredicateOp `json:"op"`
+	Value  *T          `json:"value,omitempty"`
+	Values []T         `json:"values,omitempty"`
+	From   *T          `json:"from,omitempty"`
+	To     *T          `json:"to,omitempty"`
+}
+
+// MarshalJSON writes an "in" predicate as the plain list of its values.
+func (p Predicate[T]) MarshalJSON() ([]byte, error) {
+	if p.Op == OpIn && p.Value == nil && p.From == nil && p.To == nil {
+		if p.Values == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal(p.Values)
+	}
+
+	type predicate Predicate[T]
+	return json.Marshal(predicate(p))
+}
+
+// UnmarshalJSON also accepts the plain list of a filter without predicate
+// support as an "in" predicate.
+func (p *Predicate[T]) UnmarshalJSON(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		var values []T
+		if err := json.Unmarshal(b, &values); err != nil {
+			return err
+		}
+		*p = Predicate[T]{Op: OpIn, Values: values}
+		return nil
+	}
+
+	type predicate Predicate[T]
+	return json.Unmarshal(b, (*predicate)(p))
+}
+
+// Validator is implemented by filter predicates and params.
+type Validator interface {
+	Validate() error
+}
+
+func Eq[T any](value T) *Predicate[T] {
+	return &Predicate[T]{Op: OpEq, Value: &value}
+}
+
+func In[T any](values ...T) *Predicate[T] {
+	return &Predicate[T]{Op: OpIn, Values: values}
+}
+
+func NotIn[T any](values ...T) *Predicate[T] {
+	return &Predicate[T]{Op: OpNotIn, Values: values}
+}
+
+func Prefix(value string) *Predicate[string] {
+	return &Predicate[string]{Op: OpPrefix, Value: &value}
+}
+
+func Contains(value string) *Predicate[string] {
+	return &Predicate[string]{Op: OpContains, Value: &value}
+}
+
+// Range matches values between the bounds, a nil bound is open.
+func Range[T any](from *T, to *T) *Predicate[T] {
+	return &Predicate[T]{Op: OpRange, From: from, To: to}
+}
+
+func Between[T any](from T, to T) *Predicate[T] {
+	return &Predicate[T]{Op: OpRange, From: &from, To: &to}
+}
+
+func IsNull[T any]() *Predicate[T] {
+	return &Predicate[T]{Op: OpIsNull}
+}
+
+func NotNull[T any]() *Predicate[T] {
+	return &Predicate[T]{Op: OpNotNull}
+}
+
+// Validate checks that the operands match the op. A nil predicate is
+// valid and matches everything.
+func (p *Predicate[T]) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	switch p.Op {
+	case OpEq:
+		if p.Value == nil || p.Values != nil || p.From != nil || p.To != nil {
+			return ErrInvalidPredicate
+		}
+	case OpPrefix, OpContains:
+		value, ok := any(p.Value).(*string)
+		if !ok || value == nil || *value == "" || p.Values != nil || p.From != nil || p.To != nil {
+			return ErrInvalidPredicate
+		}
+	case OpIn, OpNotIn:
+		if len(p.Values) == 0 || p.Value != nil || p.From != nil || p.To != nil {
+			return ErrInvalidPredicate
+		}
+	case OpRange:
+		if (p.From == nil && p.To == nil) || p.Value != nil || p.Values != nil {
+			return ErrInvalidPredicate
+		}
+		if p.From != nil && p.To != nil && compareBounds(*p.From, *p.To) > 0 {
+			return ErrInvalidPredicate
+		}
+	case OpIsNull, OpNotNull:
+		if p.Value != nil || p.Values != nil || p.From != nil || p.To != nil {
+			return ErrInvalidPredicate
+		}
+	default:
+		return ErrInvalidPredicate
+	}
+
+	return nil
+}
+
+// ValidateAll returns the first error of the validators.
+func ValidateAll(validators ...Validator) error {
+	for _, v := range validators {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Helper for compare range bounds of ordered types, others compare equal
+func compareBounds[T any](from T, to T) int {
+	switch f := any(from).(type) {
+	case time.Time:
+		return f.Compare(any(to).(time.Time))
+	case string:
+		return cmp.Compare(f, any(to).(string))
+	case int:
+		return cmp.Compare(f, any(to).(int))
+	case int64:
+		return cmp.Compare(f, any(to).(int64))
+	case uint:
+		return cmp.Compare(f, any(to).(uint))
+	case uint64:
+		return cmp.Compare(f, any(to).(uint64))
+	case float64:
+		return cmp.Compare(f, any(to).(float64))
+	}
+	return 0
+}
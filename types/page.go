@@ -0,0 +1,123 @@
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"iter"
+
+	"go.microcore.dev/framework/errors"
+)
+
+var (
+	ErrInvalidPageLimit  = errors.New(errors.ErrBadRequest, "invalid_limit")
+	ErrInvalidPageCursor = errors.New(errors.ErrBadRequest, "invalid_cursor")
+	ErrInvalidSort       = errors.New(errors.ErrBadRequest, "invalid_sort")
+	ErrInvalidSortOrder  = errors.New(errors.ErrBadRequest, "invalid_sort_order")
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// PageParams are the pagination and sorting params of filter requests,
+// embedded in every filter DTO. A page is selected either by Cursor or
+// by Offset. Sort is the json name of a result field.
+type PageParams struct {
+	Limit  *uint      `json:"limit,omitempty"`
+	Cursor *string    `json:"cursor,omitempty"`
+	Offset *uint      `json:"offset,omitempty"`
+	Sort   *string    `json:"sort,omitempty"`
+	Order  *SortOrder `json:"order,omitempty"`
+}
+
+// Validate checks the params before they are sent.
+func (p PageParams) Validate() error {
+	if p.Limit != nil && *p.Limit == 0 {
+		return ErrInvalidPageLimit
+	}
+	if p.Cursor != nil && p.Offset != nil {
+		return ErrInvalidPageCursor
+	}
+	if p.Sort != nil && *p.Sort == "" {
+		return ErrInvalidSort
+	}
+	if p.Order != nil && *p.Order != SortAsc && *p.Order != SortDesc {
+		return ErrInvalidSortOrder
+	}
+	return nil
+}
+
+// Page is a page of filter results. NextCursor is nil on the last page.
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+	Total      uint    `json:"total"`
+}
+
+// UnmarshalJSON also accepts a plain result list of services without
+// pagination support as a single page.
+func (p *Page[T]) UnmarshalJSON(b []byte) error {
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		var items []T
+		if err := json.Unmarshal(b, &items); err != nil {
+			return err
+		}
+		*p = Page[T]{Items: items, Total: uint(len(items))}
+		return nil
+	}
+
+	type page Page[T]
+	return json.Unmarshal(b, (*page)(p))
+}
+
+// PageFunc fetches the page at cursor, a nil cursor is the first page.
+type PageFunc[T any] func(ctx context.Context, cursor *string) (*Page[T], error)
+
+// All walks the items of all pages lazily, fetching the next page when
+// the previous one is consumed. An error is yielded once and ends the walk.
+//
+//	for user, err := range types.All(ctx, func(ctx context.Context, cursor *string) (*types.Page[users.FilterUsersResult], error) {
+//		data.Cursor = cursor
+//		return usersAdapter.FilterUsers(ctx, authToken, data)
+//	}) {
+//		...
+//	}
+func All[T any](ctx context.Context, fetch PageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var cursor *string
+		for {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			page, err := fetch(ctx, cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if page.NextCursor == nil || *page.NextCursor == "" {
+				return
+			}
+			// Guard against a service repeating the cursor
+			if cursor != nil && *cursor == *page.NextCursor {
+				var zero T
+				yield(zero, ErrInvalidPageCursor)
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}